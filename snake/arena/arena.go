@@ -3,17 +3,40 @@ package arena
 import (
 	"errors"
 	"math/rand"
+	"time"
 )
 
 type Arena interface {
 	State() State
-	Tick()
+	// Tick advances the game and returns what happened during the tick.
+	Tick() []Event
 	SetSnakeHeading(snake int, h Direction)
+	// SetSnakeSpeed sets the speed of a snake in percent of NormalSpeed.
+	SetSnakeSpeed(snake int, speed int)
 	AddSnake(x, y, size int, h Direction) (snake int, err error)
 }
 
+type Options struct {
+	Width, Height int
+	Seed          int64
+	// Wrap makes snakes leaving the arena reappear on the opposite edge.
+	Wrap  bool
+	Rules Rules
+	Walls []Position
+	// Items are the positions of the initial GROW items.
+	Items []Position
+	// SpawnTable lists the items that can appear, classic if empty.
+	SpawnTable []ItemChance
+	// MaxItems is the number of items kept in the arena, at least 1.
+	MaxItems int
+}
+
 type arena struct {
-	s State
+	s          State
+	rng        *rand.Rand
+	spawnTable []ItemChance
+	maxItems   int
+	events     []Event
 }
 
 func (a arena) State() State {
@@ -29,59 +52,167 @@ func (a arena) insideArena(p Position) bool {
 
 func (a *arena) endGame() {
 	a.s.GameIsOver = true
+	a.events = append(a.events, Event{Kind: GAME_OVER, Snake: -1, Killer: -1, Winner: a.s.Winner()})
 }
 
-func (a *arena) endGameIfAllSnakesAreDead() {
-	for _, snake := range a.s.Snakes {
-		if snake.IsAlive {
-			return
-		}
+// killSnake kills snake; killer is the snake it ran into, or -1.
+func (a *arena) killSnake(snake int, cause DeathCause, killer int) {
+	if !a.s.Snakes[snake].IsAlive {
+		return
 	}
-	a.endGame()
-}
-
-func (a *arena) killSnake(snake int) {
 	a.s.Snakes[snake].IsAlive = false
-	a.endGameIfAllSnakesAreDead()
+	a.s.Snakes[snake].DeathCause = cause
+	a.events = append(a.events, Event{Kind: SNAKE_DIED, Snake: snake, Cause: cause, Killer: killer})
+	if killer != -1 {
+		a.s.Snakes[killer].Kills++
+		a.s.Snakes[killer].Score += killPoints
+	}
 }
 
-func (a *arena) Tick() {
+// Tick moves every living snake one cell per phase, in as many phases as
+// its speed allows this tick (see steps). All snakes of a phase move at
+// the same time and collisions are resolved on the
+// resulting positions, so the order of the snakes does not matter:
+//
+//   - a head in a wall or outside the arena dies (HIT_WALL)
+//   - a head in another snake, dead or alive, dies (HIT_SNAKE) and the kill
+//     goes to that snake, unless its head ran into the victim as well
+//   - heads meeting in the same cell or swapping places all die (HIT_SNAKE)
+//   - an item goes to the head that reached it, unless several heads did
+//   - a head in its own body dies (HIT_SELF)
+//   - a tail cell left in the same phase is free, unless the snake grows
+//   - INVINCIBLE snakes only die in walls
+func (a *arena) Tick() []Event {
 	if a.s.GameIsOver {
-		return
+		return nil
 	}
+	a.events = nil
+	a.s.Ticks++
+	a.expireItems()
+	steps := make([]int, len(a.s.Snakes))
 	for id := range a.s.Snakes {
+		a.s.Snakes[id].expireEffects(a.s.Ticks)
+		steps[id] = a.steps(id)
+	}
+	for {
+		movers := []int{}
+		for id, snake := range a.s.Snakes {
+			if steps[id] > 0 && snake.IsAlive {
+				steps[id]--
+				movers = append(movers, id)
+			}
+		}
+		if len(movers) == 0 {
+			break
+		}
+		a.moveSnakes(movers)
+	}
+	for id := range a.s.Snakes {
+		if a.s.Snakes[id].IsAlive {
+			a.s.Snakes[id].TicksSurvived++
+		}
+	}
+	if a.s.isOver() {
+		a.endGame()
+	} else {
+		a.spawnItems()
+	}
+	return a.events
+}
+
+// moveSnakes moves the given snakes one cell at the same time.
+func (a *arena) moveSnakes(movers []int) {
+	for _, id := range movers {
 		snake := &a.s.Snakes[id]
-		if !snake.IsAlive {
+		snake.turn()
+		snake.extrude()
+		snake.Segments[0] = a.s.wrap(snake.Head())
+	}
+	a.pickUpItems(movers)
+	for _, id := range movers {
+		a.s.Snakes[id].applyGrowth()
+	}
+	causes := make([]DeathCause, len(movers))
+	killers := make([]int, len(movers))
+	dying := map[int]bool{}
+	for i, id := range movers {
+		causes[i], killers[i] = a.collision(id)
+		if causes[i] != NOT_DEAD {
+			dying[id] = true
+		}
+	}
+	for i, id := range movers {
+		if causes[i] == NOT_DEAD {
 			continue
 		}
-		snake.extrude()
-		if snake.Head() == a.s.PointItem {
-			a.setRandomPositionForPointItem()
-		} else {
-			snake.contractBody()
+		killer := killers[i]
+		if killer != -1 && dying[killer] && inSequence(a.s.Snakes[killer].Head(), a.s.Snakes[id].Segments) {
+			killer = -1
 		}
+		a.killSnake(id, causes[i], killer)
+	}
+}
 
-		for other_id, other_snake := range a.s.Snakes {
-			if inSequence(snake.Head(), other_snake.Segments) {
-				if id != other_id {
-					a.killSnake(id)
-				} else if inSequence(snake.Head(), other_snake.Segments[1:]) {
-					a.killSnake(id)
-				}
+// pickUpItems gives every item reached by a single head to that snake. An
+// item reached by several heads at once is lost, as the heads crash into
+// each other.
+func (a *arena) pickUpItems(movers []int) {
+	for _, id := range movers {
+		head := a.s.Snakes[id].Head()
+		i := a.s.itemAt(head)
+		if i == -1 {
+			continue
+		}
+		heads := 0
+		for _, other := range movers {
+			if a.s.Snakes[other].Head() == head {
+				heads++
 			}
 		}
+		if heads == 1 {
+			a.eatItem(id, i)
+		} else {
+			a.s.Items = append(a.s.Items[:i], a.s.Items[i+1:]...)
+		}
+	}
+}
 
-		if !a.insideArena(snake.Head()) {
-			a.killSnake(id)
+// collision tells what the head of a snake ran into after everyone moved,
+// and which snake it was, -1 if none.
+func (a arena) collision(id int) (DeathCause, int) {
+	snake := a.s.Snakes[id]
+	head := snake.Head()
+	if !a.insideArena(head) || inSequence(head, a.s.Walls) {
+		return HIT_WALL, -1
+	}
+	if snake.HasEffect(INVINCIBLE, a.s.Ticks) {
+		return NOT_DEAD, -1
+	}
+	for other_id, other_snake := range a.s.Snakes {
+		if other_id != id && inSequence(head, other_snake.Segments) {
+			return HIT_SNAKE, other_id
 		}
 	}
+	if inSequence(head, snake.Segments[1:]) {
+		return HIT_SELF, -1
+	}
+	return NOT_DEAD, -1
 }
 
+// SetSnakeHeading queues a heading change, taken on the next move of the
+// snake that has no earlier change queued.
 func (a *arena) SetSnakeHeading(snake int, h Direction) {
-	if isOpposingDirections(a.s.Snakes[snake].Heading, h) {
-		return
+	if a.s.Snakes[snake].HasEffect(REVERSE, a.s.Ticks) {
+		h = opposite(h)
+	}
+	a.s.Snakes[snake].queueTurn(h)
+}
+
+func (a *arena) SetSnakeSpeed(snake int, speed int) {
+	if speed < 1 {
+		panic("Snake speed must be positive.")
 	}
-	a.s.Snakes[snake].Heading = h
+	a.s.Snakes[snake].Speed = speed
 }
 
 func (a arena) isValidPlacementPosition(p Position) bool {
@@ -91,6 +222,9 @@ func (a arena) isValidPlacementPosition(p Position) bool {
 	if p.Y < 0 || p.Y >= a.s.Size.Y {
 		return false
 	}
+	if inSequence(p, a.s.Walls) {
+		return false
+	}
 	for _, snake := range a.s.Snakes {
 		if inSequence(p, snake.Segments) {
 			return false
@@ -113,34 +247,86 @@ func (a arena) getValidPositions() []Position {
 
 }
 
-func (a *arena) setRandomPositionForPointItem() {
-	valid_positions := a.getValidPositions()
-	if len(valid_positions) == 0 {
-		a.endGame()
-	} else {
-		a.s.PointItem = valid_positions[rand.Intn(len(valid_positions))]
-	}
-}
-
 func (a *arena) AddSnake(x, y, size int, heading Direction) (int, error) {
 	if !a.isValidPlacementPosition(Position{x, y}) {
 		return -1, errors.New("Invalid position for snake head.")
 	}
+	if !isValidDirection(heading) {
+		return -1, errors.New("Invalid heading for snake.")
+	}
+	if size < 1 {
+		return -1, errors.New("Snake size must be at least 1.")
+	}
 	new_snake := newSnake(x, y, size, heading)
-	for _, snake := range a.s.Snakes {
-		if inSequence(snake.Head(), new_snake.Segments) {
-			return -1, errors.New("Snake segment makes another snake head position invalid.")
+	for _, p := range new_snake.Segments[1:] {
+		if !a.isValidPlacementPosition(p) {
+			return -1, errors.New("Snake body would leave the arena or overlap a wall or another snake.")
 		}
 	}
 	a.s.Snakes = append(a.s.Snakes, new_snake)
 	return len(a.s.Snakes) - 1, nil
 }
 
+// DefaultSpawns places up to four snakes on the corners of a rectangle
+// spanning the middle third of the arena, the ones on the right facing
+// the ones on the left.
+func DefaultSpawns(width, height, snakes int) []Spawn {
+	one := Position{width / 3, height / 3}
+	right := width - 1 - one.X
+	spawns := []Spawn{
+		{Position{one.X, one.Y}, spawnSize, EAST},
+		{Position{one.X, one.Y * 2}, spawnSize, EAST},
+		{Position{right, one.Y}, spawnSize, WEST},
+		{Position{right, one.Y * 2}, spawnSize, WEST},
+	}
+	if snakes > len(spawns) {
+		panic("At most 4 snakes have default spawns.")
+	}
+	return spawns[:snakes]
+}
+
 func New(width, height int) Arena {
-	if width < 0 || height < 0 {
+	return NewWithOptions(Options{Width: width, Height: height, Seed: time.Now().UnixNano()})
+}
+
+// NewWithOptions creates an arena whose random choices are fully determined
+// by o.Seed, so the same options and inputs always replay the same game.
+func NewWithOptions(o Options) Arena {
+	if o.Width < 0 || o.Height < 0 {
 		panic("Arena width and height must be positive.")
 	}
-	a := arena{s: State{Size: Position{width, height}}}
-	a.setRandomPositionForPointItem()
+	if err := o.Rules.Validate(); err != nil {
+		panic(err)
+	}
+	walls := make([]Position, len(o.Walls))
+	copy(walls, o.Walls)
+	a := arena{
+		s:          State{Size: Position{o.Width, o.Height}, Seed: o.Seed, Wrap: o.Wrap, Rules: o.Rules, Walls: walls},
+		rng:        rand.New(rand.NewSource(o.Seed)),
+		spawnTable: o.SpawnTable,
+		maxItems:   o.MaxItems,
+	}
+	if len(a.spawnTable) == 0 {
+		a.spawnTable = SpawnTables["classic"]
+	}
+	total := 0
+	for _, c := range a.spawnTable {
+		if c.Weight < 0 {
+			panic("Spawn table weights must not be negative.")
+		}
+		total += c.Weight
+	}
+	if total == 0 {
+		panic("Spawn table weights must not all be zero.")
+	}
+	if a.maxItems < 1 {
+		a.maxItems = 1
+	}
+	for _, p := range o.Items {
+		if a.isValidPlacementPosition(p) && a.s.itemAt(p) == -1 {
+			a.s.Items = append(a.s.Items, Item{Position: p, Kind: GROW})
+		}
+	}
+	a.spawnItems()
 	return &a
 }
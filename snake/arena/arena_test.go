@@ -9,8 +9,10 @@ func TestArenaCreation(t *testing.T) {
 	makeArena(t, 50, 30)
 }
 
+const testSeed = 42
+
 func makeArena(t *testing.T, width, height int) Arena {
-	a := New(width, height)
+	a := NewWithOptions(Options{Width: width, Height: height, Seed: testSeed})
 	state := a.State()
 	if state.Size.X != width || state.Size.Y != height {
 		t.Error("Wrong width or height. Expected:", width, height, "Got:", state.Size.X, state.Size.Y)
@@ -165,6 +167,7 @@ func TestSnakeMovementHitSelfAndGameOver(t *testing.T) {
 	testSnakeMovement(t, a, SOUTH)
 	testSnakeMovement(t, a, WEST)
 	testSnakeMovementCausesGameOver(t, a, NORTH)
+	assertDeathCause(t, a, 0, HIT_SELF)
 }
 
 func TestSnakeMovementHitWallAndGameOver(t *testing.T) {
@@ -175,6 +178,13 @@ func TestSnakeMovementHitWallAndGameOver(t *testing.T) {
 		testSnakeMovement(t, a, EAST)
 	}
 	testSnakeMovementCausesGameOver(t, a, EAST)
+	assertDeathCause(t, a, 0, HIT_WALL)
+}
+
+func assertDeathCause(t *testing.T, a Arena, snake int, cause DeathCause) {
+	if got := a.State().Snakes[snake].DeathCause; got != cause {
+		t.Error("Wrong cause of death: Expected:", cause, "Got:", got)
+	}
 }
 
 func TestSnakeMovementForTwoSnakes(t *testing.T) {
@@ -194,7 +204,10 @@ func TestStateCopy(t *testing.T) {
 	if s1.GameIsOver != s2.GameIsOver {
 		t.Fail()
 	}
-	if s1.PointItem != s2.PointItem {
+	if len(s1.Items) != len(s2.Items) || s1.Items[0] != s2.Items[0] {
+		t.Fail()
+	}
+	if s1.Seed != s2.Seed {
 		t.Fail()
 	}
 	for i, s1snake := range s1.Snakes {
@@ -217,11 +230,55 @@ func TestStateCopy(t *testing.T) {
 	}
 }
 
+func TestStateExposesSeed(t *testing.T) {
+	a := NewWithOptions(Options{Width: 40, Height: 20, Seed: 1234})
+	if a.State().Seed != 1234 {
+		t.Error("Wrong seed in state: Expected:", 1234, "Got:", a.State().Seed)
+	}
+}
+
+func eatPointItems(a *arena, count int) []Position {
+	items := make([]Position, 0, count)
+	for i := 0; i < count; i++ {
+		items = append(items, a.s.Items[0].Position)
+		a.s.Items = nil
+		a.spawnItems()
+	}
+	return items
+}
+
+func placeItem(a Arena, kind ItemKind, p Position) {
+	a.(*arena).s.Items = []Item{{Position: p, Kind: kind}}
+}
+
+func TestSameSeedReplaysPointItems(t *testing.T) {
+	a1 := makeArena(t, 40, 20).(*arena)
+	a2 := makeArena(t, 40, 20).(*arena)
+	items1, items2 := eatPointItems(a1, 20), eatPointItems(a2, 20)
+	for i := range items1 {
+		if items1[i] != items2[i] {
+			t.Error("Point item sequences differ at:", i, items1[i], items2[i])
+		}
+	}
+}
+
+func TestDifferentSeedsDiffer(t *testing.T) {
+	a1 := NewWithOptions(Options{Width: 40, Height: 20, Seed: 1}).(*arena)
+	a2 := NewWithOptions(Options{Width: 40, Height: 20, Seed: 2}).(*arena)
+	items1, items2 := eatPointItems(a1, 20), eatPointItems(a2, 20)
+	for i := range items1 {
+		if items1[i] != items2[i] {
+			return
+		}
+	}
+	t.Error("Different seeds should produce different point item sequences.")
+}
+
 func TestSnakeMovementEatPointItemAndGrow(t *testing.T) {
 	a := makeArena(t, 40, 20)
 	initial := a.State().Snakes[0]
 
-	a.(*arena).s.PointItem = Position{initial.Head().X + 1, initial.Head().Y}
+	placeItem(a, GROW, Position{initial.Head().X + 1, initial.Head().Y})
 	a.SetSnakeHeading(0, EAST)
 
 	a.Tick()
@@ -232,17 +289,27 @@ func TestSnakeMovementEatPointItemAndGrow(t *testing.T) {
 	if s.Length() != initial.Length()+1 {
 		t.Error("Wrong snake size: Expected:", initial.Length()+1, "Got:", s.Length())
 	}
-	if s.Head() == a.State().PointItem {
-		t.Error("Point item is not eaten correctly:", a.State().PointItem)
+	if a.State().Items[0].Position == s.Head() {
+		t.Error("Point item is not eaten correctly:", a.State().Items)
 	}
 	checkSnakeMovementHead(t, initial, EAST, s)
 	checkSnakeMovementBody(t, initial, s)
 }
 
+func TestSnakeCanGrowPastInitialCapacity(t *testing.T) {
+	s := newSnake(0, 0, 1, EAST)
+	for i := 0; i < 100; i++ {
+		s.extrude()
+	}
+	if s.Length() != 101 {
+		t.Error("Wrong snake size: Expected:", 101, "Got:", s.Length())
+	}
+}
+
 func TestDeclareGameOverWhenCannotPlaceMorePointItems(t *testing.T) {
-	a := makeArena(t, 2, 1).(*arena)
-	h := a.s.Snakes[0].Head()
-	a.s.PointItem = Position{h.X + 1, h.Y}
+	a := NewWithOptions(Options{Width: 2, Height: 1, Seed: testSeed}).(*arena)
+	addSnake(t, a, 0, 0, 1, EAST)
+	placeItem(a, GROW, Position{1, 0})
 	a.Tick()
 	state := a.State()
 	if !state.GameIsOver {
@@ -313,11 +380,19 @@ func TestStatesDifferInGameOverFlag(t *testing.T) {
 func TestStatesDifferInPointItemPosition(t *testing.T) {
 	s1, s2 := makeStates(t)
 
-	s2.PointItem.X += 1
+	s2.Items = s1.copyItems()
+	s2.Items[0].X += 1
+	assertStatesDiffer(t, s1, s2)
+
+	s2.Items = s1.copyItems()
+	s2.Items[0].Y += 1
 	assertStatesDiffer(t, s1, s2)
 
-	s2.PointItem.X = s1.PointItem.X
-	s2.PointItem.Y += 1
+	s2.Items = s1.copyItems()
+	s2.Items[0].Kind = BONUS
+	assertStatesDiffer(t, s1, s2)
+
+	s2.Items = nil
 	assertStatesDiffer(t, s1, s2)
 }
 
@@ -382,6 +457,50 @@ func TestInvalidPointItemPositionsOnSnakes(t *testing.T) {
 	}
 }
 
+func TestNewSnakeHeadings(t *testing.T) {
+	cases := []struct {
+		heading Direction
+		tail    Position
+	}{
+		{EAST, Position{6, 5}}, {NORTH, Position{10, 9}}, {WEST, Position{14, 5}}, {SOUTH, Position{10, 1}},
+	}
+	for _, c := range cases {
+		a := New(20, 10)
+		addSnake(t, a, 10, 5, 5, c.heading)
+		s := a.State().Snakes[0]
+		if tail := s.Segments[len(s.Segments)-1]; tail != c.tail {
+			t.Error("Wrong tail position for heading:", c.heading, "Expected:", c.tail, "Got:", tail)
+		}
+		moveSnakes(t, a, c.heading)
+		if !a.State().Snakes[0].IsAlive {
+			t.Error("Snake should survive moving along its heading:", c.heading)
+		}
+	}
+}
+
+func TestNewSnakeBodyMustBeValid(t *testing.T) {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Walls: []Position{{5, 2}}})
+	addSnake(t, a, 10, 5, 5, EAST)
+	invalid := []Spawn{
+		{Position{1, 1}, 5, EAST},   // body leaves the arena on the left
+		{Position{17, 8}, 5, NORTH}, // body leaves the arena at the bottom
+		{Position{18, 1}, 5, WEST},  // body leaves the arena on the right
+		{Position{3, 1}, 5, SOUTH},  // body leaves the arena at the top
+		{Position{5, 5}, 5, SOUTH},  // body overlaps the wall
+		{Position{8, 2}, 5, NORTH},  // body overlaps the other snake
+		{Position{10, 8}, 0, EAST},  // empty snake
+		{Position{10, 8}, 5, 7},     // invalid heading
+	}
+	for _, spawn := range invalid {
+		if i, err := a.AddSnake(spawn.X, spawn.Y, spawn.Size, spawn.Heading); i != -1 || err == nil {
+			t.Error("Snake placement should be invalid:", spawn)
+		}
+	}
+	if len(a.State().Snakes) != 1 {
+		t.Error("Bad snakes should not be added at all.")
+	}
+}
+
 func TestNewSnakeHeadCannotBeAtInvalidPosition(t *testing.T) {
 	width, height := 40, 20
 	a := makeArena(t, width, height)
@@ -442,6 +561,49 @@ func TestReverseMotionSuicideIsInvalid(t *testing.T) {
 	testSnakeMovement(t, a, SOUTH)
 }
 
+var turnCases = []struct {
+	name  string
+	turns []Direction
+	moves []Direction
+}{
+	{"single turn", []Direction{NORTH}, []Direction{NORTH, NORTH}},
+	{"two turns in one tick", []Direction{NORTH, WEST}, []Direction{NORTH, WEST, WEST}},
+	{"three turns in one tick", []Direction{NORTH, WEST, NORTH}, []Direction{NORTH, WEST, NORTH}},
+	{"reversal is dropped", []Direction{WEST}, []Direction{EAST, EAST}},
+	{"reversal of a queued turn is dropped", []Direction{NORTH, SOUTH, EAST}, []Direction{NORTH, EAST, EAST}},
+	{"repeats are dropped", []Direction{NORTH, NORTH, EAST}, []Direction{NORTH, EAST}},
+	{"full queue drops turns", []Direction{NORTH, WEST, NORTH, EAST}, []Direction{NORTH, WEST, NORTH, NORTH}},
+}
+
+func TestFastTurnsAreQueued(t *testing.T) {
+	for _, c := range turnCases {
+		a := makeArena(t, 40, 20)
+		for _, d := range c.turns {
+			a.SetSnakeHeading(0, d)
+		}
+		for i, d := range c.moves {
+			h := a.State().Snakes[0].Head()
+			a.Tick()
+			s := a.State().Snakes[0]
+			if !s.IsAlive || s.Head() != h.Step(d) {
+				t.Error(c.name, "move:", i+1, "Expected:", h.Step(d), "Got:", s.Head(), s.DeathCause)
+			}
+		}
+	}
+}
+
+func TestQueuedTurnsAreTakenOnePerMove(t *testing.T) {
+	a := makeArena(t, 40, 20)
+	a.SetSnakeSpeed(0, 2*NormalSpeed)
+	a.SetSnakeHeading(0, NORTH)
+	a.SetSnakeHeading(0, WEST)
+	h := a.State().Snakes[0].Head()
+	a.Tick()
+	if expected := h.Step(NORTH).Step(WEST); a.State().Snakes[0].Head() != expected {
+		t.Error("Fast snake should take both turns in one tick: Expected:", expected, "Got:", a.State().Snakes[0].Head())
+	}
+}
+
 func TestGameIsOnlyOverWhenAllSnakeDies(t *testing.T) {
 	a := makeArena(t, 40, 20)
 	addSnake(t, a, 30, 15, 5, EAST)
@@ -455,4 +617,179 @@ func TestGameIsOnlyOverWhenAllSnakeDies(t *testing.T) {
 	if h.X != 30 || h.Y != 15 {
 		t.Error("Dead snakes should not move.")
 	}
+	assertDeathCause(t, a, 0, HIT_SELF)
+	assertDeathCause(t, a, 1, HIT_SELF)
+	if a.State().Snakes[0].TicksSurvived != 5 || a.State().Snakes[1].TicksSurvived != 3 {
+		t.Error("Wrong ticks survived:", a.State().Snakes[0].TicksSurvived, a.State().Snakes[1].TicksSurvived)
+	}
+}
+
+func TestKillsAreCountedForTheOtherSnake(t *testing.T) {
+	a := makeArena(t, 40, 20)
+	addSnake(t, a, 22, 13, 7, SOUTH)
+	a.Tick()
+	a.Tick()
+	s := a.State().Snakes
+	if s[0].IsAlive || s[0].DeathCause != HIT_SNAKE {
+		t.Error("Snake should have died hitting the other snake.")
+	}
+	if s[1].Kills != 1 || s[1].Score != killPoints {
+		t.Error("Kill should be counted for the other snake:", s[1].Kills, s[1].Score)
+	}
+}
+
+var collisionCases = []struct {
+	name   string
+	spawns []Spawn
+	items  []Position
+	causes []DeathCause
+	kills  []int
+	eaten  []int
+}{
+	{"head-on",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{7, 5}, 3, WEST}}, nil,
+		[]DeathCause{HIT_SNAKE, HIT_SNAKE}, []int{0, 0}, []int{0, 0}},
+	{"heads meet on an item",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{7, 5}, 3, WEST}}, []Position{{6, 5}},
+		[]DeathCause{HIT_SNAKE, HIT_SNAKE}, []int{0, 0}, []int{0, 0}},
+	{"swapping heads",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{6, 5}, 3, WEST}}, nil,
+		[]DeathCause{HIT_SNAKE, HIT_SNAKE}, []int{0, 0}, []int{0, 0}},
+	{"three heads meet",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{7, 5}, 3, WEST}, {Position{6, 6}, 3, NORTH}}, nil,
+		[]DeathCause{HIT_SNAKE, HIT_SNAKE, HIT_SNAKE}, []int{0, 0, 0}, []int{0, 0, 0}},
+	{"head into body",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{6, 6}, 3, SOUTH}}, nil,
+		[]DeathCause{HIT_SNAKE, NOT_DEAD}, []int{0, 1}, []int{0, 0}},
+	{"following a tail",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{6, 3}, 3, NORTH}}, nil,
+		[]DeathCause{NOT_DEAD, NOT_DEAD}, []int{0, 0}, []int{0, 0}},
+	{"following a growing tail",
+		[]Spawn{{Position{5, 5}, 3, EAST}, {Position{6, 3}, 3, NORTH}}, []Position{{6, 2}},
+		[]DeathCause{HIT_SNAKE, NOT_DEAD}, []int{0, 1}, []int{0, 1}},
+	{"head into wall",
+		[]Spawn{{Position{0, 5}, 1, WEST}, {Position{1, 7}, 3, NORTH}}, nil,
+		[]DeathCause{HIT_WALL, NOT_DEAD}, []int{0, 0}, []int{0, 0}},
+}
+
+func reversedSpawns(spawns []Spawn) []Spawn {
+	reversed := make([]Spawn, len(spawns))
+	for i, spawn := range spawns {
+		reversed[len(spawns)-1-i] = spawn
+	}
+	return reversed
+}
+
+// TestSimultaneousCollisions runs every case with the snakes in both orders,
+// as no snake has priority over the others.
+func TestSimultaneousCollisions(t *testing.T) {
+	for _, c := range collisionCases {
+		for _, reverse := range []bool{false, true} {
+			spawns := c.spawns
+			if reverse {
+				spawns = reversedSpawns(spawns)
+			}
+			items := append([]Position{{19, 0}}, c.items...)
+			a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Items: items})
+			for _, spawn := range spawns {
+				addSnake(t, a, spawn.X, spawn.Y, spawn.Size, spawn.Heading)
+			}
+			a.Tick()
+			s := a.State()
+			for i := range spawns {
+				expected := i
+				if reverse {
+					expected = len(spawns) - 1 - i
+				}
+				if s.Snakes[i].DeathCause != c.causes[expected] {
+					t.Error(c.name, "reversed:", reverse, "snake:", expected,
+						"Expected:", c.causes[expected], "Got:", s.Snakes[i].DeathCause)
+				}
+				if s.Snakes[i].Kills != c.kills[expected] {
+					t.Error(c.name, "reversed:", reverse, "snake:", expected,
+						"Expected kills:", c.kills[expected], "Got:", s.Snakes[i].Kills)
+				}
+				if s.Snakes[i].ItemsEaten != c.eaten[expected] {
+					t.Error(c.name, "reversed:", reverse, "snake:", expected,
+						"Expected items:", c.eaten[expected], "Got:", s.Snakes[i].ItemsEaten)
+				}
+			}
+		}
+	}
+}
+
+func TestInvincibleSnakeSurvivesHeadOn(t *testing.T) {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Items: []Position{{19, 0}}})
+	addSnake(t, a, 5, 5, 3, EAST)
+	addSnake(t, a, 7, 5, 3, WEST)
+	a.(*arena).s.Snakes[1].addEffect(INVINCIBLE, effectTicks)
+	a.Tick()
+	s := a.State()
+	assertDeathCause(t, a, 0, HIT_SNAKE)
+	if !s.Snakes[1].IsAlive || s.Snakes[1].Kills != 1 {
+		t.Error("Invincible snake should survive and get the kill:", s.Snakes[1].IsAlive, s.Snakes[1].Kills)
+	}
+}
+
+func TestWrapAroundArena(t *testing.T) {
+	a := NewWithOptions(Options{Width: 10, Height: 6, Seed: testSeed, Wrap: true})
+	placeItem(a, GROW, Position{5, 0})
+	addSnake(t, a, 8, 3, 3, EAST)
+	a.Tick()
+	a.Tick()
+	assertHeadAt(t, a, Position{0, 3})
+	a.SetSnakeHeading(0, NORTH)
+	for i := 0; i < 4; i++ {
+		a.Tick()
+	}
+	assertHeadAt(t, a, Position{0, 5})
+	if !a.State().Snakes[0].IsAlive || !a.State().Wrap {
+		t.Error("Snakes should wrap around instead of dying.")
+	}
+}
+
+func assertHeadAt(t *testing.T, a Arena, p Position) {
+	if h := a.State().Snakes[0].Head(); h != p {
+		t.Error("Wrong position for snake head: Expected:", p, "Got:", h)
+	}
+}
+
+func TestWrapDistance(t *testing.T) {
+	s := State{Size: Position{10, 6}, Wrap: true}
+	if d := s.distance(Position{0, 0}, Position{9, 5}); d != 2 {
+		t.Error("Wrong wraparound distance: Expected:", 2, "Got:", d)
+	}
+	s.Wrap = false
+	if d := s.distance(Position{0, 0}, Position{9, 5}); d != 14 {
+		t.Error("Wrong distance: Expected:", 14, "Got:", d)
+	}
+}
+
+func TestDefaultSpawnsAreValid(t *testing.T) {
+	for players := 1; players <= 4; players++ {
+		a := New(40, 20)
+		for _, spawn := range DefaultSpawns(40, 20, players) {
+			if _, err := a.AddSnake(spawn.X, spawn.Y, spawn.Size, spawn.Heading); err != nil {
+				t.Error("Default spawn should be valid:", spawn, err)
+			}
+		}
+	}
+}
+
+func TestControllers(t *testing.T) {
+	for _, kind := range ControllerKinds {
+		c, err := NewController(kind, testSeed)
+		if err != nil {
+			t.Error("Controller kind should be known:", kind)
+			continue
+		}
+		a := makeArena(t, 40, 20)
+		for i := 0; i < 10; i++ {
+			a.SetSnakeHeading(0, c.Heading(a.State(), 0))
+			a.Tick()
+		}
+	}
+	if _, err := NewController("no-such-bot", testSeed); err == nil {
+		t.Error("Unknown controller kind should be rejected.")
+	}
 }
@@ -0,0 +1,189 @@
+package arena
+
+import (
+	"math/rand"
+)
+
+var directions = []Direction{EAST, NORTH, WEST, SOUTH}
+
+func (s State) isBlocked(p Position) bool {
+	if p.X < 0 || p.X >= s.Size.X || p.Y < 0 || p.Y >= s.Size.Y {
+		return true
+	}
+	if inSequence(p, s.Walls) {
+		return true
+	}
+	for _, snake := range s.Snakes {
+		if inSequence(p, snake.Segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// safeMoves lists the headings that do not run the snake into a wall or a
+// snake on the next tick, starting with the current heading.
+func (s State) safeMoves(snake int) []Direction {
+	current := s.Snakes[snake]
+	moves := make([]Direction, 0, len(directions))
+	for _, d := range append([]Direction{current.Heading}, directions...) {
+		if isOpposingDirections(current.Heading, d) || containsDirection(moves, d) {
+			continue
+		}
+		if !s.isBlocked(s.step(current.Head(), d)) {
+			moves = append(moves, d)
+		}
+	}
+	return moves
+}
+
+func containsDirection(ds []Direction, d Direction) bool {
+	for _, item := range ds {
+		if item == d {
+			return true
+		}
+	}
+	return false
+}
+
+// reachableCells counts the free cells reachable from start.
+func (s State) reachableCells(start Position) int {
+	if s.isBlocked(start) {
+		return 0
+	}
+	seen := map[Position]bool{start: true}
+	queue := []Position{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range directions {
+			next := s.step(p, d)
+			if !seen[next] && !s.isBlocked(next) {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return len(seen)
+}
+
+// targets lists the positions of the items worth going for.
+func (s State) targets() []Position {
+	targets := []Position{}
+	for _, item := range s.Items {
+		if item.Kind.isGood() {
+			targets = append(targets, item.Position)
+		}
+	}
+	return targets
+}
+
+func (s State) nearestTarget(snake int) (Position, bool) {
+	head := s.Snakes[snake].Head()
+	targets := s.targets()
+	if len(targets) == 0 {
+		return head, false
+	}
+	nearest := targets[0]
+	for _, p := range targets[1:] {
+		if s.distance(head, p) < s.distance(head, nearest) {
+			nearest = p
+		}
+	}
+	return nearest, true
+}
+
+// pathTo returns the first heading of a shortest path from the head of the
+// snake to the nearest of targets, or false if none can be reached.
+func (s State) pathTo(snake int, targets []Position) (Direction, bool) {
+	head := s.Snakes[snake].Head()
+	first := map[Position]Direction{}
+	queue := []Position{}
+	for _, d := range s.safeMoves(snake) {
+		next := s.step(head, d)
+		first[next] = d
+		queue = append(queue, next)
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if inSequence(p, targets) {
+			return first[p], true
+		}
+		for _, d := range directions {
+			next := s.step(p, d)
+			if _, seen := first[next]; !seen && next != head && !s.isBlocked(next) {
+				first[next] = first[p]
+				queue = append(queue, next)
+			}
+		}
+	}
+	return 0, false
+}
+
+func randomSafe(rng *rand.Rand, s State, snake int) Direction {
+	moves := s.safeMoves(snake)
+	if len(moves) == 0 {
+		return straight(s, snake)
+	}
+	return moves[rng.Intn(len(moves))]
+}
+
+func closestTo(s State, snake int, moves []Direction) Direction {
+	best := moves[0]
+	target, ok := s.nearestTarget(snake)
+	if !ok {
+		return best
+	}
+	head := s.Snakes[snake].Head()
+	for _, d := range moves[1:] {
+		if s.distance(s.step(head, d), target) < s.distance(s.step(head, best), target) {
+			best = d
+		}
+	}
+	return best
+}
+
+func greedy(s State, snake int) Direction {
+	moves := s.safeMoves(snake)
+	if len(moves) == 0 {
+		return straight(s, snake)
+	}
+	return closestTo(s, snake, moves)
+}
+
+func shortestPath(s State, snake int) Direction {
+	if d, ok := s.pathTo(snake, s.targets()); ok {
+		return d
+	}
+	return survival(s, snake)
+}
+
+// survival prefers moves that leave at least as much room as the snake is
+// long, heading for the nearest item among those, and otherwise the move that
+// leaves the most room.
+func survival(s State, snake int) Direction {
+	moves := s.safeMoves(snake)
+	if len(moves) == 0 {
+		return straight(s, snake)
+	}
+	head := s.Snakes[snake].Head()
+	roomy := []Direction{}
+	largest, largestArea := moves[0], -1
+	for _, d := range moves {
+		area := s.reachableCells(s.step(head, d))
+		if area >= s.Snakes[snake].Length() {
+			roomy = append(roomy, d)
+		}
+		if area > largestArea {
+			largest, largestArea = d, area
+		}
+	}
+	if len(roomy) == 0 {
+		return largest
+	}
+	if d, ok := s.pathTo(snake, s.targets()); ok && containsDirection(roomy, d) {
+		return d
+	}
+	return closestTo(s, snake, roomy)
+}
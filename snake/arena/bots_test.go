@@ -0,0 +1,117 @@
+package arena
+
+import (
+	"testing"
+)
+
+func pocketState() State {
+	return State{
+		Size: Position{10, 6},
+		Snakes: []Snake{
+			{Segments: []Position{{5, 2}, {5, 3}, {5, 4}, {5, 5}}, Heading: NORTH, IsAlive: true},
+			{Segments: []Position{{4, 0}, {4, 1}, {6, 1}, {6, 0}}},
+		},
+		Items: []Item{{Position: Position{5, 0}}},
+	}
+}
+
+func TestBotsIgnoreBadItems(t *testing.T) {
+	s := pocketState()
+	s.Items[0].Kind = SHRINK
+	if targets := s.targets(); len(targets) != 0 {
+		t.Error("Bots should not go for bad items:", targets)
+	}
+	s.Items = append(s.Items, Item{Position: Position{0, 5}, Kind: BONUS})
+	if p, _ := s.nearestTarget(0); p != (Position{0, 5}) {
+		t.Error("Wrong target:", p)
+	}
+}
+
+func TestGreedyHeadsForPointItem(t *testing.T) {
+	if d := greedy(pocketState(), 0); d != NORTH {
+		t.Error("Greedy bot should head for the point item: Expected:", NORTH, "Got:", d)
+	}
+}
+
+func TestSurvivalAvoidsEnclosingItself(t *testing.T) {
+	if d := survival(pocketState(), 0); d == NORTH {
+		t.Error("Survival bot should not enter a pocket smaller than itself.")
+	}
+}
+
+func TestShortestPathGoesAroundObstacles(t *testing.T) {
+	s := State{
+		Size: Position{10, 6},
+		Snakes: []Snake{
+			{Segments: []Position{{2, 2}, {1, 2}}, Heading: EAST, IsAlive: true},
+			{Segments: []Position{{3, 0}, {3, 1}, {3, 2}, {3, 3}}},
+		},
+		Items: []Item{{Position: Position{4, 2}}},
+	}
+	if d := shortestPath(s, 0); d != SOUTH {
+		t.Error("BFS bot should go around the obstacle: Expected:", SOUTH, "Got:", d)
+	}
+	if _, ok := s.pathTo(0, []Position{{3, 1}}); ok {
+		t.Error("Blocked cells should not be reachable.")
+	}
+}
+
+func TestSafeMovesAvoidWallsAndSnakes(t *testing.T) {
+	s := State{
+		Size:   Position{3, 3},
+		Snakes: []Snake{{Segments: []Position{{2, 0}, {1, 0}}, Heading: EAST, IsAlive: true}},
+	}
+	moves := s.safeMoves(0)
+	if len(moves) != 1 || moves[0] != SOUTH {
+		t.Error("Only SOUTH should be safe, got:", moves)
+	}
+}
+
+func TestSafeBotsSurvive(t *testing.T) {
+	for _, kind := range []string{"random-safe", "greedy", "bfs", "flood"} {
+		c, _ := NewController(kind, testSeed)
+		a := makeArena(t, 40, 20)
+		for i := 0; i < 50; i++ {
+			a.SetSnakeHeading(0, c.Heading(a.State(), 0))
+			a.Tick()
+		}
+		if !a.State().Snakes[0].IsAlive {
+			t.Error("Bot should survive an empty arena:", kind)
+		}
+	}
+}
+
+func TestReversedBotsAvoidWalls(t *testing.T) {
+	for _, kind := range []string{"random-safe", "greedy", "bfs", "flood"} {
+		c, _ := NewController(kind, testSeed)
+		a := NewWithOptions(Options{Width: 10, Height: 5, Seed: testSeed, Items: []Position{{X: 0, Y: 4}}})
+		addSnake(t, a, 9, 0, 3, EAST)
+		a.(*arena).s.Snakes[0].addEffect(REVERSE, effectTicks)
+		a.SetSnakeHeading(0, c.Heading(a.State(), 0))
+		a.Tick()
+		if s := a.State().Snakes[0]; !s.IsAlive || s.Heading != SOUTH {
+			t.Error("Reversed bot should turn away from the walls:", kind, s.Heading, s.DeathCause)
+		}
+	}
+}
+
+func TestSlowBotsDoNotQueueTurns(t *testing.T) {
+	c, _ := NewController("flood", testSeed)
+	a := makeArena(t, 40, 20)
+	a.SetSnakeSpeed(0, 30)
+	moved := 0
+	for i := 0; i < 100; i++ {
+		s := a.State()
+		if s.WillMove(0) {
+			moved++
+			a.SetSnakeHeading(0, c.Heading(s, 0))
+		}
+		a.Tick()
+		if turns := a.(*arena).s.Snakes[0].turns; len(turns) > 0 {
+			t.Fatal("Turns should be taken on the tick they are planned for:", turns)
+		}
+	}
+	if moved != 30 || !a.State().Snakes[0].IsAlive {
+		t.Error("Slow bot should move 30 times and survive, moved:", moved)
+	}
+}
@@ -0,0 +1,70 @@
+package arena
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Controller steers a snake: given the current state and the index of the
+// snake it controls, it returns the heading for the next tick.
+type Controller interface {
+	Heading(s State, snake int) Direction
+}
+
+type ControllerFunc func(s State, snake int) Direction
+
+func (f ControllerFunc) Heading(s State, snake int) Direction {
+	return f(s, snake)
+}
+
+var ControllerKinds = []string{"straight", "random", "random-safe", "greedy", "bfs", "flood"}
+
+// NewController returns a controller of the given kind. Its headings
+// account for REVERSE, which the arena flips.
+func NewController(kind string, seed int64) (Controller, error) {
+	c, err := newController(kind, seed)
+	if err != nil {
+		return nil, err
+	}
+	return ControllerFunc(func(s State, snake int) Direction {
+		d := c.Heading(s, snake)
+		if s.Snakes[snake].HasEffect(REVERSE, s.Ticks) {
+			return opposite(d)
+		}
+		return d
+	}), nil
+}
+
+func newController(kind string, seed int64) (Controller, error) {
+	rng := rand.New(rand.NewSource(seed))
+	switch kind {
+	case "straight":
+		return ControllerFunc(straight), nil
+	case "random":
+		return ControllerFunc(func(s State, snake int) Direction {
+			return randomTurn(rng, s, snake)
+		}), nil
+	case "random-safe":
+		return ControllerFunc(func(s State, snake int) Direction {
+			return randomSafe(rng, s, snake)
+		}), nil
+	case "greedy":
+		return ControllerFunc(greedy), nil
+	case "bfs":
+		return ControllerFunc(shortestPath), nil
+	case "flood":
+		return ControllerFunc(survival), nil
+	}
+	return nil, errors.New("Unknown controller kind: " + kind)
+}
+
+func straight(s State, snake int) Direction {
+	return s.Snakes[snake].Heading
+}
+
+func randomTurn(rng *rand.Rand, s State, snake int) Direction {
+	if rng.Intn(5) != 0 {
+		return straight(s, snake)
+	}
+	return Direction(rng.Intn(4))
+}
@@ -0,0 +1,40 @@
+package arena
+
+type EventKind int
+
+const (
+	ITEM_EATEN = EventKind(iota)
+	ITEM_SPAWNED
+	SNAKE_DIED
+	GAME_OVER
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case ITEM_EATEN:
+		return "item-eaten"
+	case ITEM_SPAWNED:
+		return "item-spawned"
+	case SNAKE_DIED:
+		return "snake-died"
+	case GAME_OVER:
+		return "game-over"
+	}
+	return "unknown"
+}
+
+// Event is something that happened during a tick. Only the fields used by
+// its kind are set:
+//
+//	ITEM_EATEN    Snake, Item
+//	ITEM_SPAWNED  Item
+//	SNAKE_DIED    Snake, Cause, Killer (-1 if none)
+//	GAME_OVER     Winner (-1 for a draw)
+type Event struct {
+	Kind   EventKind
+	Snake  int
+	Item   Item
+	Cause  DeathCause
+	Killer int
+	Winner int
+}
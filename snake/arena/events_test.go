@@ -0,0 +1,68 @@
+package arena
+
+import (
+	"testing"
+)
+
+func eventKinds(events []Event) []EventKind {
+	kinds := []EventKind{}
+	for _, e := range events {
+		kinds = append(kinds, e.Kind)
+	}
+	return kinds
+}
+
+func assertEventKinds(t *testing.T, events []Event, expected ...EventKind) {
+	got := eventKinds(events)
+	if len(got) != len(expected) {
+		t.Error("Wrong events: Expected:", expected, "Got:", got)
+		return
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Error("Wrong events: Expected:", expected, "Got:", got)
+			return
+		}
+	}
+}
+
+func TestTickReportsEatenAndSpawnedItems(t *testing.T) {
+	a := makeArena(t, 40, 20)
+	h := a.State().Snakes[0].Head()
+	placeItem(a, BONUS, h.Step(EAST))
+	events := a.Tick()
+	assertEventKinds(t, events, ITEM_EATEN, ITEM_SPAWNED)
+	if events[0].Snake != 0 || events[0].Item.Kind != BONUS || events[0].Item.Position != h.Step(EAST) {
+		t.Error("Wrong eaten item event:", events[0])
+	}
+	if events[1].Item != a.State().Items[0] {
+		t.Error("Wrong spawned item event: Expected:", a.State().Items[0], "Got:", events[1].Item)
+	}
+	assertEventKinds(t, a.Tick())
+}
+
+func TestTickReportsDeathsAndGameOver(t *testing.T) {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Items: []Position{{19, 0}}})
+	addSnake(t, a, 5, 5, 3, EAST)
+	addSnake(t, a, 6, 6, 3, SOUTH)
+	addSnake(t, a, 15, 2, 3, EAST)
+	events := a.Tick()
+	assertEventKinds(t, events, SNAKE_DIED)
+	if events[0].Snake != 0 || events[0].Cause != HIT_SNAKE || events[0].Killer != 1 {
+		t.Error("Wrong death event:", events[0])
+	}
+	ticks(a, 2)
+	events = a.Tick()
+	assertEventKinds(t, events, SNAKE_DIED)
+	if events[0].Snake != 1 || events[0].Cause != HIT_WALL || events[0].Killer != -1 {
+		t.Error("Wrong death event:", events[0])
+	}
+	events = a.Tick()
+	assertEventKinds(t, events, SNAKE_DIED, GAME_OVER)
+	if events[1].Winner != 2 {
+		t.Error("Wrong winner: Expected:", 2, "Got:", events[1].Winner)
+	}
+	if a.Tick() != nil {
+		t.Error("Ticks after the game is over should report nothing.")
+	}
+}
@@ -0,0 +1,206 @@
+package arena
+
+type ItemKind int
+
+const (
+	GROW = ItemKind(iota)
+	SHRINK
+	SPEED_UP
+	SLOW_DOWN
+	INVINCIBLE
+	REVERSE
+	BONUS
+)
+
+func (k ItemKind) String() string {
+	switch k {
+	case GROW:
+		return "grow"
+	case SHRINK:
+		return "shrink"
+	case SPEED_UP:
+		return "speed-up"
+	case SLOW_DOWN:
+		return "slow-down"
+	case INVINCIBLE:
+		return "invincible"
+	case REVERSE:
+		return "reverse"
+	case BONUS:
+		return "bonus"
+	}
+	return "unknown"
+}
+
+const NormalSpeed = 100
+
+const (
+	effectTicks = 50
+	shrinkBy    = 2
+	bonusGrowth = 3
+	killPoints  = 3
+)
+
+// itemPoints is the score for eating an item, 0 for the rest.
+var itemPoints = map[ItemKind]int{GROW: 1, BONUS: 5}
+
+type Item struct {
+	Position
+	Kind ItemKind
+	// Expires is the tick at which the item disappears, 0 if it never does.
+	Expires int
+}
+
+// ItemChance is an entry of a spawn table: Kind is picked with probability
+// proportional to Weight and disappears after Lifetime ticks unless it is
+// zero.
+type ItemChance struct {
+	Kind     ItemKind
+	Weight   int
+	Lifetime int
+}
+
+var SpawnTables = map[string][]ItemChance{
+	"classic": {{GROW, 1, 0}},
+	"mixed": {
+		{GROW, 12, 0},
+		{SHRINK, 2, 100},
+		{SPEED_UP, 2, 100},
+		{SLOW_DOWN, 2, 100},
+		{INVINCIBLE, 1, 60},
+		{REVERSE, 1, 100},
+		{BONUS, 2, 40},
+	},
+}
+
+// isGood tells whether bots should go for an item.
+func (k ItemKind) isGood() bool {
+	return k != SHRINK && k != SLOW_DOWN && k != REVERSE
+}
+
+func (s State) itemAt(p Position) int {
+	for i, item := range s.Items {
+		if item.Position == p {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s State) copyItems() []Item {
+	items := make([]Item, len(s.Items))
+	copy(items, s.Items)
+	return items
+}
+
+func (a *arena) pickItemKind() ItemChance {
+	total := 0
+	for _, c := range a.spawnTable {
+		total += c.Weight
+	}
+	n := a.rng.Intn(total)
+	for _, c := range a.spawnTable {
+		if n < c.Weight {
+			return c
+		}
+		n -= c.Weight
+	}
+	panic("Unreachable.")
+}
+
+// spawnItems tops the arena up to the maximum number of items. The game is
+// over when there is nothing left to eat and no room for a new item.
+func (a *arena) spawnItems() {
+	for len(a.s.Items) < a.maxItems {
+		valid_positions := a.freeItemPositions()
+		if len(valid_positions) == 0 {
+			if len(a.s.Items) == 0 {
+				a.endGame()
+			}
+			return
+		}
+		c := a.pickItemKind()
+		item := Item{Position: valid_positions[a.rng.Intn(len(valid_positions))], Kind: c.Kind}
+		if c.Lifetime > 0 {
+			item.Expires = a.s.Ticks + c.Lifetime
+		}
+		a.s.Items = append(a.s.Items, item)
+		a.events = append(a.events, Event{Kind: ITEM_SPAWNED, Snake: -1, Item: item, Killer: -1})
+	}
+}
+
+func (a arena) freeItemPositions() []Position {
+	positions := a.getValidPositions()
+	free := positions[:0]
+	for _, p := range positions {
+		if a.s.itemAt(p) == -1 {
+			free = append(free, p)
+		}
+	}
+	return free
+}
+
+func (a *arena) expireItems() {
+	items := a.s.Items[:0]
+	for _, item := range a.s.Items {
+		if item.Expires == 0 || item.Expires > a.s.Ticks {
+			items = append(items, item)
+		}
+	}
+	a.s.Items = items
+}
+
+func (a *arena) eatItem(snake, index int) {
+	item := a.s.Items[index]
+	a.s.Items = append(a.s.Items[:index], a.s.Items[index+1:]...)
+	s := &a.s.Snakes[snake]
+	a.events = append(a.events, Event{Kind: ITEM_EATEN, Snake: snake, Item: item, Killer: -1})
+	s.ItemsEaten++
+	s.Score += itemPoints[item.Kind]
+	switch item.Kind {
+	case GROW:
+		s.growth += 1
+	case BONUS:
+		s.growth += bonusGrowth
+	case SHRINK:
+		s.growth -= shrinkBy
+	case SPEED_UP:
+		delete(s.Effects, SLOW_DOWN)
+		s.addEffect(SPEED_UP, a.s.Ticks+effectTicks)
+	case SLOW_DOWN:
+		delete(s.Effects, SPEED_UP)
+		s.addEffect(SLOW_DOWN, a.s.Ticks+effectTicks)
+	default:
+		s.addEffect(item.Kind, a.s.Ticks+effectTicks)
+	}
+}
+
+// speed is the speed of the snake at tick with the effects of items.
+func (s Snake) speed(tick int) int {
+	switch {
+	case s.HasEffect(SPEED_UP, tick):
+		return s.Speed * 2
+	case s.HasEffect(SLOW_DOWN, tick):
+		return s.Speed / 2
+	}
+	return s.Speed
+}
+
+// WillMove tells whether a living snake moves on the next tick. Controllers
+// are only asked for a heading then, so slow snakes do not queue turns
+// planned from positions they have not left yet.
+func (s State) WillMove(snake int) bool {
+	sn := s.Snakes[snake]
+	return sn.IsAlive && sn.progress+sn.speed(s.Ticks+1) >= NormalSpeed
+}
+
+// steps is the number of cells a snake moves in the current tick. Its
+// speed, doubled or halved by items, adds up over the ticks until it makes
+// up whole cells, so a snake at 150% moves one and two cells in turn.
+func (a *arena) steps(snake int) int {
+	s := &a.s.Snakes[snake]
+	s.progress += s.speed(a.s.Ticks)
+	steps := s.progress / NormalSpeed
+	s.progress %= NormalSpeed
+	return steps
+}
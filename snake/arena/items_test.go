@@ -0,0 +1,197 @@
+package arena
+
+import (
+	"testing"
+)
+
+func eatItem(t *testing.T, kind ItemKind) Arena {
+	a := makeArena(t, 40, 20)
+	h := a.State().Snakes[0].Head()
+	placeItem(a, kind, Position{h.X + 1, h.Y})
+	a.Tick()
+	if len(a.(*arena).s.Items) == 0 || a.State().Items[0].Position == a.State().Snakes[0].Head() {
+		t.Error("Item should have been eaten and replaced:", kind)
+	}
+	return a
+}
+
+func ticks(a Arena, n int) {
+	for i := 0; i < n; i++ {
+		a.Tick()
+	}
+}
+
+func TestBonusItemGrowsSnake(t *testing.T) {
+	a := eatItem(t, BONUS)
+	ticks(a, 5)
+	if l := a.State().Snakes[0].Length(); l != 5+bonusGrowth {
+		t.Error("Wrong snake size: Expected:", 5+bonusGrowth, "Got:", l)
+	}
+}
+
+func TestShrinkItemShrinksSnake(t *testing.T) {
+	a := eatItem(t, SHRINK)
+	if l := a.State().Snakes[0].Length(); l != 5-shrinkBy {
+		t.Error("Wrong snake size: Expected:", 5-shrinkBy, "Got:", l)
+	}
+	placeItem(a, SHRINK, a.State().Snakes[0].Head().Step(EAST))
+	a.Tick()
+	if l := a.State().Snakes[0].Length(); l != minLength {
+		t.Error("Snakes should not shrink below:", minLength, "Got:", l)
+	}
+}
+
+func TestSpeedItems(t *testing.T) {
+	a := eatItem(t, SPEED_UP)
+	h := a.State().Snakes[0].Head()
+	a.Tick()
+	if d := a.State().Snakes[0].Head().X - h.X; d != 2 {
+		t.Error("Sped up snake should move 2 cells per tick, moved:", d)
+	}
+
+	a = eatItem(t, SLOW_DOWN)
+	h = a.State().Snakes[0].Head()
+	ticks(a, 4)
+	if d := a.State().Snakes[0].Head().X - h.X; d != 2 {
+		t.Error("Slowed down snake should move every other tick, moved:", d)
+	}
+}
+
+func TestEffectsWearOff(t *testing.T) {
+	a := NewWithOptions(Options{Width: 40, Height: 20, Seed: testSeed, Wrap: true})
+	addSnake(t, a, 20, 10, 5, EAST)
+	placeItem(a, SPEED_UP, Position{21, 10})
+	ticks(a, effectTicks)
+	if !a.State().Snakes[0].HasEffect(SPEED_UP, a.State().Ticks) {
+		t.Error("Effect should not have worn off yet.")
+	}
+	a.Tick()
+	if a.State().Snakes[0].HasEffect(SPEED_UP, a.State().Ticks) {
+		t.Error("Effect should have worn off.")
+	}
+	if len(a.State().Snakes[0].Effects) != 0 {
+		t.Error("Expired effects should be removed:", a.State().Snakes[0].Effects)
+	}
+}
+
+func TestInvincibleSnakePassesThroughSnakes(t *testing.T) {
+	a := eatItem(t, INVINCIBLE)
+	h := a.State().Snakes[0].Head()
+	addSnake(t, a, h.X+3, h.Y+2, 5, NORTH)
+	ticks(a, 4)
+	if !a.State().Snakes[0].IsAlive {
+		t.Error("Invincible snake should survive hitting another snake.")
+	}
+}
+
+func TestReverseItemReversesControls(t *testing.T) {
+	a := eatItem(t, REVERSE)
+	a.SetSnakeHeading(0, NORTH)
+	a.Tick()
+	if d := a.State().Snakes[0].Heading; d != SOUTH {
+		t.Error("Controls should be reversed: Expected:", SOUTH, "Got:", d)
+	}
+}
+
+func TestItemsExpire(t *testing.T) {
+	a := makeArena(t, 40, 20)
+	a.(*arena).s.Items = []Item{{Position: Position{1, 1}, Kind: BONUS, Expires: 2}}
+	a.Tick()
+	if a.State().Items[0].Position != (Position{1, 1}) {
+		t.Error("Item should not have expired yet.")
+	}
+	a.Tick()
+	if a.State().Items[0].Position == (Position{1, 1}) {
+		t.Error("Item should have expired:", a.State().Items)
+	}
+}
+
+func TestMaxItemsAndSpawnTable(t *testing.T) {
+	table := []ItemChance{{BONUS, 1, 10}, {SHRINK, 0, 0}}
+	a := NewWithOptions(Options{Width: 40, Height: 20, Seed: testSeed, SpawnTable: table, MaxItems: 5})
+	items := a.State().Items
+	if len(items) != 5 {
+		t.Error("Wrong number of items: Expected:", 5, "Got:", len(items))
+	}
+	for i, item := range items {
+		if item.Kind != BONUS || item.Expires != 10 {
+			t.Error("Item does not match the spawn table:", item)
+		}
+		for _, other := range items[i+1:] {
+			if item.Position == other.Position {
+				t.Error("Items should not overlap:", item)
+			}
+		}
+	}
+}
+
+func TestClassicItemsAreGrowOnly(t *testing.T) {
+	a := makeArena(t, 40, 20)
+	for i := 0; i < 10; i++ {
+		eatPointItems(a.(*arena), 1)
+		if item := a.State().Items[0]; item.Kind != GROW || item.Expires != 0 {
+			t.Error("Classic items should only be GROW items:", item)
+		}
+	}
+}
+
+func TestScoreIsSeparateFromLength(t *testing.T) {
+	a := eatItem(t, GROW)
+	placeItem(a, SHRINK, a.State().Snakes[0].Head().Step(EAST))
+	a.Tick()
+	placeItem(a, BONUS, a.State().Snakes[0].Head().Step(EAST))
+	a.Tick()
+	s := a.State().Snakes[0]
+	if s.Score != itemPoints[GROW]+itemPoints[BONUS] {
+		t.Error("Wrong score: Expected:", itemPoints[GROW]+itemPoints[BONUS], "Got:", s.Score)
+	}
+	if s.ItemsEaten != 3 {
+		t.Error("Wrong number of items eaten: Expected:", 3, "Got:", s.ItemsEaten)
+	}
+	if s.TicksSurvived != 3 {
+		t.Error("Wrong number of ticks survived: Expected:", 3, "Got:", s.TicksSurvived)
+	}
+}
+
+func TestSnakeSpeedAccumulates(t *testing.T) {
+	cases := []struct {
+		speed int
+		moves []int
+	}{
+		{NormalSpeed, []int{1, 1, 1, 1}},
+		{50, []int{0, 1, 0, 1}},
+		{150, []int{1, 2, 1, 2}},
+		{75, []int{0, 1, 1, 1}},
+	}
+	for _, c := range cases {
+		a := NewWithOptions(Options{Width: 40, Height: 20, Seed: testSeed, Wrap: true, Items: []Position{{0, 0}}})
+		addSnake(t, a, 20, 10, 5, EAST)
+		a.SetSnakeSpeed(0, c.speed)
+		for i, expected := range c.moves {
+			h := a.State().Snakes[0].Head()
+			a.Tick()
+			if d := a.State().Snakes[0].Head().X - h.X; d != expected {
+				t.Error("Wrong move at speed:", c.speed, "tick:", i+1, "Expected:", expected, "Got:", d)
+			}
+		}
+	}
+}
+
+func TestSpeedItemsScaleSnakeSpeed(t *testing.T) {
+	a := eatItem(t, SPEED_UP)
+	a.SetSnakeSpeed(0, 150)
+	h := a.State().Snakes[0].Head()
+	ticks(a, 2)
+	if d := a.State().Snakes[0].Head().X - h.X; d != 6 {
+		t.Error("Sped up snake at 150% should move 6 cells in 2 ticks, moved:", d)
+	}
+}
+
+func TestInvalidSnakeSpeedPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Setting a zero speed should panic.")
+		}
+	}()
+	makeArena(t, 40, 20).SetSnakeSpeed(0, 0)
+}
@@ -0,0 +1,129 @@
+package arena
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const spawnSize = 5
+
+// Level is the layout of an arena: its size, walls, initial items and the
+// spawn points of up to four snakes.
+type Level struct {
+	Width, Height int
+	Walls         []Position
+	Items         []Position
+	Spawns        []Spawn
+}
+
+func DefaultLevel(width, height int) Level {
+	return Level{Width: width, Height: height, Spawns: DefaultSpawns(width, height, 4)}
+}
+
+// Options returns o with the size, walls and items of the level.
+func (l Level) Options(o Options) Options {
+	o.Width, o.Height = l.Width, l.Height
+	o.Walls, o.Items = l.Walls, l.Items
+	return o
+}
+
+// Validate checks that snakes can be placed on the first spawn points.
+func (l Level) Validate(snakes int) error {
+	if snakes > len(l.Spawns) {
+		return errors.New("The level has not enough spawn points.")
+	}
+	a := NewWithOptions(l.Options(Options{}))
+	for i, spawn := range l.Spawns[:snakes] {
+		if _, err := a.AddSnake(spawn.X, spawn.Y, spawn.Size, spawn.Heading); err != nil {
+			return fmt.Errorf("Spawn point %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+func LoadLevel(path string) (Level, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Level{}, err
+	}
+	defer f.Close()
+	return ParseLevel(f)
+}
+
+// headingMarkers is indexed by Direction.
+const headingMarkers = ">^<v"
+
+// ParseLevel reads a plain-text map. Every line is a row of the arena:
+//
+//	#        wall
+//	*        initial item
+//	1-4      snake spawn point (the head)
+//	> ^ < v  heading of the spawn point next to it, pointing away from it
+//	. space  empty cell
+//
+// Spawn points without a heading marker face EAST.
+func ParseLevel(r io.Reader) (Level, error) {
+	rows := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		rows = append(rows, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return Level{}, err
+	}
+	l := Level{Height: len(rows)}
+	spawns := map[int]Position{}
+	for y, row := range rows {
+		x := 0
+		for _, c := range row {
+			p := Position{x, y}
+			switch {
+			case c == '#':
+				l.Walls = append(l.Walls, p)
+			case c == '*':
+				l.Items = append(l.Items, p)
+			case c >= '1' && c <= '4':
+				if _, ok := spawns[int(c-'1')]; ok {
+					return Level{}, fmt.Errorf("Line %d: duplicate spawn point %c.", y+1, c)
+				}
+				spawns[int(c-'1')] = p
+			case c == '.' || c == ' ':
+			default:
+				if !strings.ContainsRune(headingMarkers, c) {
+					return Level{}, fmt.Errorf("Line %d: unknown map character %q.", y+1, c)
+				}
+			}
+			x++
+		}
+		if x > l.Width {
+			l.Width = x
+		}
+	}
+	for i := 0; i < len(spawns); i++ {
+		p, ok := spawns[i]
+		if !ok {
+			return Level{}, errors.New("Spawn points must be numbered from 1 without gaps.")
+		}
+		l.Spawns = append(l.Spawns, Spawn{Position: p, Size: spawnSize, Heading: spawnHeading(rows, p)})
+	}
+	return l, nil
+}
+
+func spawnHeading(rows []string, p Position) Direction {
+	for _, d := range directions {
+		marker := rune(headingMarkers[d])
+		next := p.Step(d)
+		if next.Y < 0 || next.Y >= len(rows) {
+			continue
+		}
+		row := []rune(rows[next.Y])
+		if next.X >= 0 && next.X < len(row) && row[next.X] == marker {
+			return d
+		}
+	}
+	return EAST
+}
@@ -0,0 +1,128 @@
+package arena
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLevel = `#####
+#1>*#
+#.^2#
+#####`
+
+func TestParseLevel(t *testing.T) {
+	l, err := ParseLevel(strings.NewReader(testLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Width != 5 || l.Height != 4 {
+		t.Error("Wrong level size:", l.Width, l.Height)
+	}
+	if len(l.Walls) != 14 {
+		t.Error("Wrong number of walls: Expected:", 14, "Got:", len(l.Walls))
+	}
+	if len(l.Items) != 1 || l.Items[0] != (Position{3, 1}) {
+		t.Error("Wrong items:", l.Items)
+	}
+	expected := []Spawn{
+		{Position: Position{1, 1}, Size: spawnSize, Heading: EAST},
+		{Position: Position{3, 2}, Size: spawnSize, Heading: EAST},
+	}
+	if len(l.Spawns) != len(expected) {
+		t.Fatal("Wrong spawns:", l.Spawns)
+	}
+	for i := range expected {
+		if l.Spawns[i] != expected[i] {
+			t.Error("Wrong spawn: Expected:", expected[i], "Got:", l.Spawns[i])
+		}
+	}
+}
+
+func TestParseLevelHeadingMarkers(t *testing.T) {
+	l, err := ParseLevel(strings.NewReader(".^....\n.1.<2.\n......\n.3....\n.v...."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, heading := range []Direction{NORTH, WEST, SOUTH} {
+		if l.Spawns[i].Heading != heading {
+			t.Error("Wrong heading for spawn:", i+1, "Expected:", heading, "Got:", l.Spawns[i].Heading)
+		}
+	}
+}
+
+func TestParseLevelErrors(t *testing.T) {
+	for _, level := range []string{"#1#1#", "#2#", "#x#"} {
+		if _, err := ParseLevel(strings.NewReader(level)); err == nil {
+			t.Error("Level should be invalid:", level)
+		}
+	}
+}
+
+func TestBundledLevels(t *testing.T) {
+	paths, _ := filepath.Glob("../maps/*.txt")
+	if len(paths) == 0 {
+		t.Fatal("No bundled levels found.")
+	}
+	for _, path := range paths {
+		l, err := LoadLevel(path)
+		if err != nil {
+			t.Error(path, err)
+			continue
+		}
+		if err := l.Validate(len(l.Spawns)); err != nil {
+			t.Error(path, err)
+		}
+	}
+}
+
+func TestValidateLevel(t *testing.T) {
+	l, _ := ParseLevel(strings.NewReader("#1>..\n.....\n..<2#"))
+	if err := l.Validate(1); err == nil {
+		t.Error("Spawn point 1 overlaps a wall.")
+	}
+	l, _ = ParseLevel(strings.NewReader("1>...\n.....\n...<2"))
+	if err := l.Validate(2); err == nil {
+		t.Error("Spawn point 1 leaves the level.")
+	}
+	l, _ = ParseLevel(strings.NewReader("....1>\n......\n<2...."))
+	if err := l.Validate(2); err != nil {
+		t.Error("Level should be valid:", err)
+	}
+	if err := l.Validate(3); err == nil {
+		t.Error("Level has only 2 spawn points.")
+	}
+}
+
+func TestWallsKillSnakes(t *testing.T) {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Walls: []Position{{12, 5}}})
+	addSnake(t, a, 10, 5, 5, EAST)
+	a.Tick()
+	a.Tick()
+	if a.State().Snakes[0].IsAlive {
+		t.Error("Snake should die when hitting a wall.")
+	}
+	assertDeathCause(t, a, 0, HIT_WALL)
+}
+
+func TestWallsAreNotValidPlacementPositions(t *testing.T) {
+	wall := Position{3, 3}
+	a := NewWithOptions(Options{Width: 4, Height: 4, Seed: testSeed, Walls: []Position{wall}}).(*arena)
+	if a.isValidPlacementPosition(wall) {
+		t.Error("Walls should not be valid placement positions.")
+	}
+	if len(a.getValidPositions()) != 15 {
+		t.Error("Wrong number of valid positions:", len(a.getValidPositions()))
+	}
+	if _, err := a.AddSnake(wall.X, wall.Y, 1, EAST); err == nil {
+		t.Error("Snake head should not be placed on a wall.")
+	}
+}
+
+func TestInitialPointItem(t *testing.T) {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Items: []Position{{4, 7}}})
+	items := a.State().Items
+	if len(items) != 1 || items[0].Position != (Position{4, 7}) || items[0].Kind != GROW {
+		t.Error("Initial point item should be used:", items)
+	}
+}
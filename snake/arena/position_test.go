@@ -19,7 +19,7 @@ var differing_positions = [][2]arena.Position{
 
 func TestPositionsAreEqual(t *testing.T) {
 	for _, p1 := range positions {
-		p2 := arena.Position{p1.X, p1.Y}
+		p2 := arena.Position{X: p1.X, Y: p1.Y}
 		assertPositionsAreEqual(t, p1, p2)
 	}
 }
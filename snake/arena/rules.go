@@ -0,0 +1,213 @@
+package arena
+
+import (
+	"errors"
+)
+
+type Goal int
+
+const (
+	// SURVIVAL games end when every snake is dead.
+	SURVIVAL = Goal(iota)
+	// LAST_SNAKE_STANDING games end when at most one snake is alive.
+	LAST_SNAKE_STANDING
+	// FIRST_TO_LENGTH games end when a snake reaches Rules.Length.
+	FIRST_TO_LENGTH
+	// TIMED games end after Rules.Ticks ticks.
+	TIMED
+)
+
+func (g Goal) String() string {
+	switch g {
+	case SURVIVAL:
+		return "survival"
+	case LAST_SNAKE_STANDING:
+		return "last-standing"
+	case FIRST_TO_LENGTH:
+		return "length"
+	case TIMED:
+		return "timed"
+	}
+	return "unknown"
+}
+
+var Goals = map[string]Goal{
+	"survival":      SURVIVAL,
+	"last-standing": LAST_SNAKE_STANDING,
+	"length":        FIRST_TO_LENGTH,
+	"timed":         TIMED,
+}
+
+// Rules decide when a game is over and who won it. Every game is also over
+// once all snakes are dead.
+type Rules struct {
+	Goal Goal
+	// Length is the length to reach in FIRST_TO_LENGTH games.
+	Length int
+	// Ticks is the duration of TIMED games.
+	Ticks int
+}
+
+func (r Rules) Validate() error {
+	switch r.Goal {
+	case SURVIVAL, LAST_SNAKE_STANDING:
+	case FIRST_TO_LENGTH:
+		if r.Length <= minLength {
+			return errors.New("The length to reach must be more than 2.")
+		}
+	case TIMED:
+		if r.Ticks < 1 {
+			return errors.New("Timed games must last at least 1 tick.")
+		}
+	default:
+		return errors.New("Unknown goal.")
+	}
+	return nil
+}
+
+func (s State) isOver() bool {
+	alive := 0
+	for _, snake := range s.Snakes {
+		if snake.IsAlive {
+			alive++
+		}
+	}
+	if alive == 0 {
+		return len(s.Snakes) > 0
+	}
+	switch s.Rules.Goal {
+	case LAST_SNAKE_STANDING:
+		return len(s.Snakes) > 1 && alive == 1
+	case FIRST_TO_LENGTH:
+		for _, snake := range s.Snakes {
+			if snake.Length() >= s.Rules.Length {
+				return true
+			}
+		}
+	case TIMED:
+		return s.Ticks >= s.Rules.Ticks
+	}
+	return false
+}
+
+// rank orders snakes for Winner, the highest rank wins:
+//
+//	SURVIVAL, LAST_SNAKE_STANDING  ticks survived, then score
+//	FIRST_TO_LENGTH                reaching the length, then survival
+//	TIMED                          score, then ticks survived
+func (s State) rank(snake Snake) []int {
+	switch s.Rules.Goal {
+	case FIRST_TO_LENGTH:
+		reached := 0
+		if snake.Length() >= s.Rules.Length {
+			reached = snake.Length()
+		}
+		return []int{reached, snake.TicksSurvived, snake.Score}
+	case TIMED:
+		return []int{snake.Score, snake.TicksSurvived}
+	}
+	return []int{snake.TicksSurvived, snake.Score}
+}
+
+func compareRanks(r1, r2 []int) int {
+	for i := range r1 {
+		if r1[i] != r2[i] {
+			return r1[i] - r2[i]
+		}
+	}
+	return 0
+}
+
+// Winner is the snake ranked first under the rules of the game, or -1 if
+// the game is a draw.
+func (s State) Winner() int {
+	best := -1
+	draw := false
+	for i, snake := range s.Snakes {
+		if best == -1 {
+			best = i
+			continue
+		}
+		switch c := compareRanks(s.rank(snake), s.rank(s.Snakes[best])); {
+		case c > 0:
+			best, draw = i, false
+		case c == 0:
+			draw = true
+		}
+	}
+	if draw {
+		return -1
+	}
+	return best
+}
+
+// Match is a best-of series of games: the first snake to win more than
+// half of them wins the match. Otherwise the match ends after all games,
+// drawn games included, and the snake with the most wins takes it.
+type Match struct {
+	Games int
+	Wins  []int
+	Draws int
+}
+
+func NewMatch(games, snakes int) *Match {
+	if games < 1 {
+		panic("A match must have at least 1 game.")
+	}
+	return &Match{Games: games, Wins: make([]int, snakes)}
+}
+
+// Record adds the result of a game, -1 for a draw.
+func (m *Match) Record(winner int) {
+	if winner == -1 {
+		m.Draws++
+	} else {
+		m.Wins[winner]++
+	}
+}
+
+func (m Match) Played() int {
+	played := m.Draws
+	for _, wins := range m.Wins {
+		played += wins
+	}
+	return played
+}
+
+// leader is the snake with the most wins, -1 if several share them.
+func (m Match) leader() int {
+	best, draw := -1, false
+	for i, wins := range m.Wins {
+		switch {
+		case best == -1 || wins > m.Wins[best]:
+			best, draw = i, false
+		case wins == m.Wins[best]:
+			draw = true
+		}
+	}
+	if draw {
+		return -1
+	}
+	return best
+}
+
+// Winner is the snake that won the match, -1 while it is undecided or if
+// it ended in a draw.
+func (m Match) Winner() int {
+	if !m.IsOver() {
+		return -1
+	}
+	return m.leader()
+}
+
+func (m Match) IsOver() bool {
+	if m.Played() >= m.Games {
+		return true
+	}
+	for _, wins := range m.Wins {
+		if wins > m.Games/2 {
+			return true
+		}
+	}
+	return false
+}
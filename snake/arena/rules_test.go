@@ -0,0 +1,147 @@
+package arena
+
+import (
+	"testing"
+)
+
+func makeRulesArena(t *testing.T, r Rules) Arena {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Rules: r, Items: []Position{{19, 0}}})
+	addSnake(t, a, 5, 3, 3, EAST)
+	addSnake(t, a, 5, 6, 3, EAST)
+	return a
+}
+
+func TestSurvivalGameLastsUntilAllSnakesAreDead(t *testing.T) {
+	a := makeRulesArena(t, Rules{})
+	a.SetSnakeHeading(0, NORTH)
+	ticks(a, 4)
+	if a.State().Snakes[0].IsAlive || a.State().GameIsOver {
+		t.Error("Game should go on after the first death.")
+	}
+	ticks(a, 11)
+	if !a.State().GameIsOver || a.State().Winner() != 1 {
+		t.Error("Longest surviving snake should win:", a.State().GameIsOver, a.State().Winner())
+	}
+}
+
+func TestLastSnakeStandingWins(t *testing.T) {
+	a := makeRulesArena(t, Rules{Goal: LAST_SNAKE_STANDING})
+	a.SetSnakeHeading(0, NORTH)
+	ticks(a, 4)
+	s := a.State()
+	if !s.GameIsOver || !s.Snakes[1].IsAlive || s.Winner() != 1 {
+		t.Error("Game should end with one snake alive:", s.GameIsOver, s.Winner())
+	}
+}
+
+func TestLastSnakeStandingAloneGoesOn(t *testing.T) {
+	a := NewWithOptions(Options{Width: 20, Height: 10, Seed: testSeed, Rules: Rules{Goal: LAST_SNAKE_STANDING}})
+	addSnake(t, a, 5, 3, 3, EAST)
+	a.Tick()
+	if a.State().GameIsOver {
+		t.Error("A single snake should play until it dies.")
+	}
+}
+
+func TestFirstToLengthWins(t *testing.T) {
+	a := makeRulesArena(t, Rules{Goal: FIRST_TO_LENGTH, Length: 4})
+	placeItem(a, GROW, Position{7, 6})
+	a.Tick()
+	s := a.State()
+	if s.GameIsOver {
+		t.Error("Game should not be over before the snake has grown.")
+	}
+	a.Tick()
+	s = a.State()
+	if !s.GameIsOver || s.Winner() != 1 {
+		t.Error("Snake reaching the length should win:", s.GameIsOver, s.Winner())
+	}
+}
+
+func TestTimedGameEndsWithHighestScore(t *testing.T) {
+	a := makeRulesArena(t, Rules{Goal: TIMED, Ticks: 3})
+	placeItem(a, BONUS, Position{6, 3})
+	ticks(a, 2)
+	if a.State().GameIsOver {
+		t.Error("Timed game should not end early.")
+	}
+	a.Tick()
+	s := a.State()
+	if !s.GameIsOver || s.Ticks != 3 || s.Winner() != 0 {
+		t.Error("Highest score should win when the time is up:", s.GameIsOver, s.Ticks, s.Winner())
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	for _, r := range []Rules{{Goal: FIRST_TO_LENGTH, Length: 2}, {Goal: TIMED}, {Goal: Goal(42)}} {
+		if r.Validate() == nil {
+			t.Error("Rules should be invalid:", r)
+		}
+	}
+}
+
+func TestStateWinner(t *testing.T) {
+	cases := []struct {
+		rules  Rules
+		snakes []Snake
+		winner int
+	}{
+		{Rules{}, []Snake{{TicksSurvived: 10}, {TicksSurvived: 20}}, 1},
+		{Rules{}, []Snake{{TicksSurvived: 20, Score: 6}, {TicksSurvived: 20, Score: 5}}, 0},
+		{Rules{}, []Snake{{TicksSurvived: 20, Score: 5}, {TicksSurvived: 20, Score: 5}}, -1},
+		{Rules{}, []Snake{{TicksSurvived: 5}}, 0},
+		{Rules{Goal: TIMED}, []Snake{{TicksSurvived: 10, Score: 6}, {TicksSurvived: 20, Score: 5}}, 0},
+		{Rules{Goal: FIRST_TO_LENGTH, Length: 3},
+			[]Snake{{Segments: make([]Position, 2), TicksSurvived: 20}, {Segments: make([]Position, 3), TicksSurvived: 10}}, 1},
+	}
+	for _, c := range cases {
+		if got := (State{Rules: c.rules, Snakes: c.snakes}).Winner(); got != c.winner {
+			t.Error("Wrong winner for:", c.rules, c.snakes, "Expected:", c.winner, "Got:", got)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	m := NewMatch(5, 2)
+	for _, winner := range []int{0, -1, 1, 1} {
+		m.Record(winner)
+		if m.IsOver() {
+			t.Error("Match should not be over after:", m)
+		}
+	}
+	m.Record(1)
+	if !m.IsOver() || m.Winner() != 1 || m.Played() != 5 {
+		t.Error("Wrong match result: Expected:", 1, 5, "Got:", m.Winner(), m.Played())
+	}
+}
+
+func TestMatchEndsEarlyWithAMajority(t *testing.T) {
+	m := NewMatch(3, 2)
+	m.Record(0)
+	m.Record(0)
+	if !m.IsOver() || m.Winner() != 0 {
+		t.Error("Two wins should decide a best of 3:", m)
+	}
+}
+
+func TestDrawnMatches(t *testing.T) {
+	cases := []struct {
+		name    string
+		snakes  int
+		winners []int
+		winner  int
+	}{
+		{"drawn games", 2, []int{0, -1, 1}, -1},
+		{"three-way split", 3, []int{0, 1, 2}, -1},
+		{"leader without majority", 3, []int{0, -1, -1}, 0},
+	}
+	for _, c := range cases {
+		m := NewMatch(3, c.snakes)
+		for _, winner := range c.winners {
+			m.Record(winner)
+		}
+		if !m.IsOver() || m.Winner() != c.winner {
+			t.Error(c.name, "should be over. Expected winner:", c.winner, "Got:", m.IsOver(), m.Winner())
+		}
+	}
+}
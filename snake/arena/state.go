@@ -25,6 +25,29 @@ func isOpposingDirections(h1, h2 Direction) bool {
 	return false
 }
 
+type DeathCause int
+
+const (
+	NOT_DEAD = DeathCause(iota)
+	HIT_WALL
+	HIT_SELF
+	HIT_SNAKE
+)
+
+func (c DeathCause) String() string {
+	switch c {
+	case NOT_DEAD:
+		return "none"
+	case HIT_WALL:
+		return "wall"
+	case HIT_SELF:
+		return "self"
+	case HIT_SNAKE:
+		return "snake"
+	}
+	return "unknown"
+}
+
 type Position struct {
 	X, Y int
 }
@@ -39,15 +62,74 @@ func (p Position) Equal(other Position) bool {
 	return true
 }
 
+func (p Position) Step(d Direction) Position {
+	switch d {
+	case EAST:
+		p.X += 1
+	case NORTH:
+		p.Y -= 1
+	case WEST:
+		p.X -= 1
+	case SOUTH:
+		p.Y += 1
+	}
+	return p
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+type Spawn struct {
+	Position
+	Size    int
+	Heading Direction
+}
+
 type State struct {
 	Size       Position
+	Seed       int64
+	Wrap       bool
+	Rules      Rules
+	Walls      []Position
 	Snakes     []Snake
-	PointItem  Position
+	Items      []Item
+	Ticks      int
 	GameIsOver bool
 }
 
 // TODO: consider providing deep Copy for state.
 
+// wrap moves positions that left a wraparound arena to the opposite edge.
+func (s State) wrap(p Position) Position {
+	if !s.Wrap {
+		return p
+	}
+	p.X = (p.X%s.Size.X + s.Size.X) % s.Size.X
+	p.Y = (p.Y%s.Size.Y + s.Size.Y) % s.Size.Y
+	return p
+}
+
+func (s State) step(p Position, d Direction) Position {
+	return s.wrap(p.Step(d))
+}
+
+func (s State) distance(p1, p2 Position) int {
+	dx, dy := abs(p1.X-p2.X), abs(p1.Y-p2.Y)
+	if s.Wrap {
+		if s.Size.X-dx < dx {
+			dx = s.Size.X - dx
+		}
+		if s.Size.Y-dy < dy {
+			dy = s.Size.Y - dy
+		}
+	}
+	return dx + dy
+}
+
 func (s State) Equal(other State) bool {
 	if s.Size != other.Size {
 		return false
@@ -58,21 +140,37 @@ func (s State) Equal(other State) bool {
 	if s.GameIsOver != other.GameIsOver {
 		return false
 	}
-	if s.PointItem != other.PointItem {
+	if len(s.Items) != len(other.Items) {
 		return false
 	}
+	for i := range s.Items {
+		if s.Items[i] != other.Items[i] {
+			return false
+		}
+	}
 	return true
 }
 
 func (s State) Copy() State {
 	return State{
 		Size:       s.Size,
+		Seed:       s.Seed,
+		Wrap:       s.Wrap,
+		Rules:      s.Rules,
+		Walls:      s.copyWalls(),
 		Snakes:     s.copySnakes(),
-		PointItem:  s.PointItem,
+		Items:      s.copyItems(),
+		Ticks:      s.Ticks,
 		GameIsOver: s.GameIsOver,
 	}
 }
 
+func (s State) copyWalls() []Position {
+	walls := make([]Position, len(s.Walls))
+	copy(walls, s.Walls)
+	return walls
+}
+
 func (s State) copySnakes() []Snake {
 	snakes := make([]Snake, len(s.Snakes))
 	for i, snake := range s.Snakes {
@@ -81,10 +179,32 @@ func (s State) copySnakes() []Snake {
 	return snakes
 }
 
+const (
+	minLength      = 2
+	maxQueuedTurns = 3
+)
+
 type Snake struct {
-	Segments []Position
-	Heading  Direction
-	IsAlive  bool
+	Segments   []Position
+	Heading    Direction
+	IsAlive    bool
+	DeathCause DeathCause
+	// Score is kept apart from the length, which items can also shrink.
+	Score         int
+	Kills         int
+	ItemsEaten    int
+	TicksSurvived int
+	// Effects maps the active item effects to the tick they wear off.
+	Effects map[ItemKind]int
+	// Speed is in percent of NormalSpeed, one cell per tick.
+	Speed int
+	// growth is the number of segments still to grow, negative when
+	// shrinking.
+	growth int
+	// progress is the part of a cell moved so far, in percent.
+	progress int
+	// turns are the headings of the next moves, see queueTurn.
+	turns []Direction
 }
 
 func (s Snake) Equal(other Snake) bool {
@@ -110,21 +230,17 @@ func (s Snake) Length() int {
 	return len(s.Segments)
 }
 
+// QueuedTurns is the number of heading changes waiting for the next moves.
+func (s Snake) QueuedTurns() int {
+	return len(s.turns)
+}
+
 func (s *Snake) moveHead() {
-	switch s.Heading {
-	case EAST:
-		s.Segments[0].X += 1
-	case NORTH:
-		s.Segments[0].Y -= 1
-	case WEST:
-		s.Segments[0].X -= 1
-	case SOUTH:
-		s.Segments[0].Y += 1
-	}
+	s.Segments[0] = s.Segments[0].Step(s.Heading)
 }
 
 func (s *Snake) extrudeBody() {
-	s.Segments = s.Segments[:len(s.Segments)+1]
+	s.Segments = append(s.Segments, Position{})
 	for i := len(s.Segments) - 1; i > 0; i-- {
 		s.Segments[i] = s.Segments[i-1]
 	}
@@ -134,6 +250,63 @@ func (s *Snake) contractBody() {
 	s.Segments = s.Segments[:len(s.Segments)-1]
 }
 
+// queueTurn adds a heading change for the next moves, one per move, so
+// quick key presses within a tick are not lost. Turns opposite to the
+// heading the snake will have by then are dropped, as are repeats and
+// turns past maxQueuedTurns.
+func (s *Snake) queueTurn(h Direction) {
+	last := s.Heading
+	if len(s.turns) > 0 {
+		last = s.turns[len(s.turns)-1]
+	}
+	if !isValidDirection(h) || h == last || isOpposingDirections(last, h) || len(s.turns) >= maxQueuedTurns {
+		return
+	}
+	s.turns = append(s.turns, h)
+}
+
+// turn takes the next queued heading, if any.
+func (s *Snake) turn() {
+	if len(s.turns) > 0 {
+		s.Heading = s.turns[0]
+		s.turns = s.turns[1:]
+	}
+}
+
+// applyGrowth finishes a move: the tail follows the head unless the snake
+// is growing, and extra tail segments are dropped while it shrinks.
+func (s *Snake) applyGrowth() {
+	if s.growth > 0 {
+		s.growth--
+		return
+	}
+	s.contractBody()
+	for ; s.growth < 0; s.growth++ {
+		if s.Length() > minLength {
+			s.contractBody()
+		}
+	}
+}
+
+func (s Snake) HasEffect(k ItemKind, tick int) bool {
+	return s.Effects[k] > tick
+}
+
+func (s *Snake) addEffect(k ItemKind, until int) {
+	if s.Effects == nil {
+		s.Effects = map[ItemKind]int{}
+	}
+	s.Effects[k] = until
+}
+
+func (s *Snake) expireEffects(tick int) {
+	for k := range s.Effects {
+		if !s.HasEffect(k, tick) {
+			delete(s.Effects, k)
+		}
+	}
+}
+
 func (s *Snake) extrude() {
 	s.extrudeBody()
 	s.moveHead()
@@ -142,20 +315,53 @@ func (s *Snake) extrude() {
 func (s Snake) Copy() Snake {
 	segments := make([]Position, len(s.Segments))
 	copy(segments, s.Segments)
-	return Snake{Segments: segments, Heading: s.Heading, IsAlive: s.IsAlive}
+	var effects map[ItemKind]int
+	if s.Effects != nil {
+		effects = make(map[ItemKind]int, len(s.Effects))
+		for k, until := range s.Effects {
+			effects[k] = until
+		}
+	}
+	return Snake{
+		Segments:      segments,
+		Heading:       s.Heading,
+		IsAlive:       s.IsAlive,
+		DeathCause:    s.DeathCause,
+		Score:         s.Score,
+		Kills:         s.Kills,
+		ItemsEaten:    s.ItemsEaten,
+		TicksSurvived: s.TicksSurvived,
+		Effects:       effects,
+		Speed:         s.Speed,
+		growth:        s.growth,
+		progress:      s.progress,
+		turns:         append([]Direction(nil), s.turns...),
+	}
+}
+
+func isValidDirection(d Direction) bool {
+	return d >= EAST && d <= SOUTH
+}
+
+func opposite(d Direction) Direction {
+	return (d + 2) % 4
 }
 
+// newSnake lays out the body of the snake behind its head, opposite to
+// its heading.
 func newSnake(x, y, size int, heading Direction) Snake {
-	if heading != EAST {
-		panic("TODO: Other headings are not implemented.")
+	if !isValidDirection(heading) {
+		panic("Invalid heading.")
 	}
 	if size < 0 {
 		panic("Size should be positive.")
 	}
 	segments := make([]Position, size, size*10)
-	s := Snake{Segments: segments, IsAlive: true}
+	s := Snake{Segments: segments, Heading: heading, IsAlive: true, Speed: NormalSpeed}
+	p := Position{x, y}
 	for i := 0; i < size; i++ {
-		s.Segments[i] = Position{x - i, y}
+		s.Segments[i] = p
+		p = p.Step(opposite(heading))
 	}
 	return s
 }
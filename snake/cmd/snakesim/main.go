@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/arena"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var games, max_ticks int
+	var seed int64
+	var width, height int
+	var controllers, format, map_file, items, goal string
+	c := Config{}
+	flag.IntVar(&games, "games", 100, "The number of games to simulate.")
+	flag.IntVar(&width, "width", 40, "Arena width.")
+	flag.IntVar(&height, "height", 20, "Arena height.")
+	flag.StringVar(&map_file, "map", "", "Load the arena from a map file instead.")
+	flag.StringVar(&controllers, "bots", "random,random", "Comma separated controller kind for each snake. (1-4 snakes)")
+	flag.BoolVar(&c.Options.Wrap, "wrap", false, "Use a wraparound arena.")
+	flag.StringVar(&items, "items", "classic", "Item set. (classic, mixed)")
+	flag.IntVar(&c.Options.MaxItems, "max-items", 1, "The number of items in the arena.")
+	flag.StringVar(&goal, "rules", "survival", "How a game is won. (survival, last-standing, length, timed)")
+	flag.IntVar(&c.Options.Rules.Length, "length", 20, "The length to reach with -rules length.")
+	flag.IntVar(&c.Options.Rules.Ticks, "time", 600, "The number of ticks of a game with -rules timed.")
+	flag.IntVar(&max_ticks, "max-ticks", 10000, "Stop a game after this many ticks.")
+	flag.Int64Var(&seed, "seed", 1, "Seed of the first game, incremented for every further game.")
+	flag.StringVar(&format, "format", "json", "Output format. (json, csv)")
+	flag.Parse()
+
+	c.Controllers = strings.Split(controllers, ",")
+	c.MaxTicks = max_ticks
+	c.Level = arena.DefaultLevel(width, height)
+	table, ok := arena.SpawnTables[items]
+	if !ok {
+		fail("Unknown item set: " + items)
+	}
+	c.Options.SpawnTable = table
+	if c.Options.Rules.Goal, ok = arena.Goals[goal]; !ok {
+		fail("Unknown rules: " + goal)
+	}
+	if err := c.Options.Rules.Validate(); err != nil {
+		fail(err.Error())
+	}
+	if map_file != "" {
+		level, err := arena.LoadLevel(map_file)
+		if err != nil {
+			fail(err.Error())
+		}
+		c.Level = level
+	}
+	if len(c.Controllers) > 4 {
+		fail("At most 4 snakes are supported.")
+	}
+
+	var write func(GameStats) error
+	switch format {
+	case "json":
+		write = jsonWriter(os.Stdout)
+	case "csv":
+		write = csvWriter(os.Stdout)
+	default:
+		fail("Unknown output format: " + format)
+	}
+
+	for game := 0; game < games; game++ {
+		stats, err := runGame(c, game, seed+int64(game))
+		if err != nil {
+			fail(err.Error())
+		}
+		if err := write(stats); err != nil {
+			fail(err.Error())
+		}
+	}
+}
+
+func fail(msg string) {
+	fmt.Fprintln(os.Stderr, msg)
+	os.Exit(1)
+}
+
+func jsonWriter(w io.Writer) func(GameStats) error {
+	enc := json.NewEncoder(w)
+	return func(stats GameStats) error {
+		return enc.Encode(stats)
+	}
+}
+
+func csvWriter(w io.Writer) func(GameStats) error {
+	out := csv.NewWriter(w)
+	header := false
+	return func(stats GameStats) error {
+		if !header {
+			out.Write([]string{"game", "seed", "ticks", "winner", "snake", "controller", "score", "length", "kills", "items_eaten", "ticks_survived", "cause_of_death"})
+			header = true
+		}
+		for i, s := range stats.Snakes {
+			out.Write([]string{
+				strconv.Itoa(stats.Game), strconv.FormatInt(stats.Seed, 10),
+				strconv.Itoa(stats.Ticks), strconv.Itoa(stats.Winner),
+				strconv.Itoa(i), s.Controller, strconv.Itoa(s.Score),
+				strconv.Itoa(s.Length), strconv.Itoa(s.Kills),
+				strconv.Itoa(s.ItemsEaten), strconv.Itoa(s.Survived), s.Cause,
+			})
+		}
+		out.Flush()
+		return out.Error()
+	}
+}
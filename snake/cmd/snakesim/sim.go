@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+type Config struct {
+	Level       arena.Level
+	Controllers []string
+	MaxTicks    int
+	// Options are used for every game, with the seed of the game.
+	Options arena.Options
+}
+
+type SnakeStats struct {
+	Controller string `json:"controller"`
+	Score      int    `json:"score"`
+	Length     int    `json:"length"`
+	Kills      int    `json:"kills"`
+	ItemsEaten int    `json:"items_eaten"`
+	Survived   int    `json:"ticks_survived"`
+	Cause      string `json:"cause_of_death"`
+}
+
+type GameStats struct {
+	Game   int          `json:"game"`
+	Seed   int64        `json:"seed"`
+	Ticks  int          `json:"ticks"`
+	Winner int          `json:"winner"`
+	Snakes []SnakeStats `json:"snakes"`
+}
+
+func runGame(c Config, game int, seed int64) (GameStats, error) {
+	if len(c.Controllers) > len(c.Level.Spawns) {
+		return GameStats{}, errors.New("The level has not enough spawn points.")
+	}
+	o := c.Options
+	o.Seed = seed
+	a := arena.NewWithOptions(c.Level.Options(o))
+	controllers := make([]arena.Controller, len(c.Controllers))
+	for i, kind := range c.Controllers {
+		controller, err := arena.NewController(kind, seed+int64(i)+1)
+		if err != nil {
+			return GameStats{}, err
+		}
+		controllers[i] = controller
+	}
+	for _, spawn := range c.Level.Spawns[:len(controllers)] {
+		if _, err := a.AddSnake(spawn.X, spawn.Y, spawn.Size, spawn.Heading); err != nil {
+			return GameStats{}, err
+		}
+	}
+
+	s := a.State()
+	tick := 0
+	for ; tick < c.MaxTicks && !s.GameIsOver; tick++ {
+		for i, controller := range controllers {
+			if s.WillMove(i) {
+				a.SetSnakeHeading(i, controller.Heading(s, i))
+			}
+		}
+		a.Tick()
+		s = a.State()
+	}
+
+	stats := GameStats{Game: game, Seed: seed, Ticks: tick, Snakes: make([]SnakeStats, len(s.Snakes))}
+	for i, snake := range s.Snakes {
+		stats.Snakes[i] = SnakeStats{
+			Controller: c.Controllers[i],
+			Score:      snake.Score,
+			Length:     snake.Length(),
+			Kills:      snake.Kills,
+			ItemsEaten: snake.ItemsEaten,
+			Survived:   snake.TicksSurvived,
+			Cause:      snake.DeathCause.String(),
+		}
+	}
+	stats.Winner = s.Winner()
+	return stats, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+func TestRunGameIsDeterministic(t *testing.T) {
+	c := Config{Level: arena.DefaultLevel(30, 15), Controllers: []string{"random", "random", "straight"}, MaxTicks: 1000}
+	s1, err := runGame(c, 0, 99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, _ := runGame(c, 0, 99)
+	if !reflect.DeepEqual(s1, s2) {
+		t.Error("Games with the same seed should match:", s1, s2)
+	}
+	if len(s1.Snakes) != 3 {
+		t.Error("Wrong number of snakes:", len(s1.Snakes))
+	}
+}
+
+func TestRunGameStopsAtMaxTicks(t *testing.T) {
+	c := Config{Level: arena.DefaultLevel(30, 15), Controllers: []string{"straight"}, MaxTicks: 3}
+	s, _ := runGame(c, 0, 1)
+	if s.Ticks != 3 || s.Snakes[0].Cause != "none" {
+		t.Error("Game should stop after max ticks:", s)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"github.com/dragonfi/go-retro/snake/arena"
+	"github.com/dragonfi/go-retro/snake/scores"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"strconv"
+)
+
+const (
+	defaultSpeed = 10
+	maxSpeed     = 30
+)
+
+var speedPresets = map[string]int{
+	"slow":   6,
+	"normal": defaultSpeed,
+	"fast":   15,
+	"insane": 25,
+}
+
+// parseSpeed accepts a preset name or a number of ticks per second.
+func parseSpeed(s string) (int, error) {
+	if speed, ok := speedPresets[s]; ok {
+		return speed, nil
+	}
+	speed, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("Unknown speed: " + s)
+	}
+	return speed, nil
+}
+
+type Config struct {
+	Players int
+	Bots    int
+	BotKind string
+	// BotSpeed is the speed of bots in percent of the players' speed.
+	BotSpeed int
+	Seed     int64
+	Wrap     bool
+	// Items names one of arena.SpawnTables.
+	Items    string
+	MaxItems int
+	// Goal names one of arena.Goals, Length and TimeLimit are its settings.
+	Goal      string
+	Length    int
+	TimeLimit int
+	// Rounds is the number of games of a best-of match.
+	Rounds int
+	// Speed is in ticks per second. Progressive games speed up as the
+	// snakes grow.
+	Speed       int
+	Progressive bool
+	// Level replaces the default arena layout when set.
+	Level *arena.Level
+	// Controls replaces DefaultControls when set.
+	Controls Controls
+	// Wide draws every arena cell two columns wide, Glyphs names one of
+	// glyphThemes.
+	Wide   bool
+	Glyphs string
+	// Theme names one of colorThemes, Colors is what the terminal can show.
+	Theme  string
+	Colors screen.ColorMode
+	// Scores keeps the high scores, none are kept if it is nil.
+	Scores *scores.Store
+}
+
+func (c Config) Validate() error {
+	if c.Players < 1 || c.Players+c.Bots > 4 || c.Bots < 0 {
+		return errors.New("Number of players and bots must be between 1 and 4.")
+	}
+	if c.Level != nil {
+		if err := c.Level.Validate(c.Players + c.Bots); err != nil {
+			return err
+		}
+	}
+	if _, err := arena.NewController(c.BotKind, 0); err != nil {
+		return err
+	}
+	if c.BotSpeed < 10 || c.BotSpeed > 300 {
+		return errors.New("Bot speed must be between 10 and 300 percent.")
+	}
+	if _, ok := arena.SpawnTables[c.Items]; !ok {
+		return errors.New("Unknown item set: " + c.Items)
+	}
+	if c.MaxItems < 1 {
+		return errors.New("There must be at least 1 item.")
+	}
+	if _, ok := arena.Goals[c.Goal]; !ok {
+		return errors.New("Unknown rules: " + c.Goal)
+	}
+	if err := c.Rules().Validate(); err != nil {
+		return err
+	}
+	if c.Rounds < 1 {
+		return errors.New("A match must have at least 1 round.")
+	}
+	if c.Speed < 1 || c.Speed > maxSpeed {
+		return errors.New("Speed must be between 1 and 30 ticks per second.")
+	}
+	if _, ok := glyphThemes[c.Glyphs]; !ok {
+		return errors.New("Unknown glyphs: " + c.Glyphs)
+	}
+	if _, ok := colorThemes[c.Theme]; !ok {
+		return errors.New("Unknown theme: " + c.Theme)
+	}
+	if c.Controls != nil {
+		if err := c.Controls.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Config) Options(seed int64) arena.Options {
+	return arena.Options{
+		Seed:       seed,
+		Wrap:       c.Wrap,
+		SpawnTable: arena.SpawnTables[c.Items],
+		MaxItems:   c.MaxItems,
+		Rules:      c.Rules(),
+	}
+}
+
+// display returns the settings of c that only change how a game looks.
+func (c Config) display() Config {
+	return Config{Wide: c.Wide, Glyphs: c.Glyphs, Theme: c.Theme, Colors: c.Colors}
+}
+
+func (c Config) Rules() arena.Rules {
+	return arena.Rules{Goal: arena.Goals[c.Goal], Length: c.Length, Ticks: c.TimeLimit}
+}
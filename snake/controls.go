@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Controls maps actions to the keys bound to them. A key is either the
+// name of a special key (see keyNames) or a single character.
+type Controls map[string][]string
+
+// actions lists every action that can be bound, in the order they are
+// shown on the controls screen.
+var actions = []string{
+	"p1.up", "p1.down", "p1.left", "p1.right",
+	"p2.up", "p2.down", "p2.left", "p2.right",
+	"p3.up", "p3.down", "p3.left", "p3.right",
+	"p4.up", "p4.down", "p4.left", "p4.right",
+	"pause", "step", "restart", "quit", "controls",
+}
+
+var DefaultControls = Controls{
+	"p1.up":    {"Up"},
+	"p1.down":  {"Down"},
+	"p1.left":  {"Left"},
+	"p1.right": {"Right"},
+	"p2.up":    {"w", "W"},
+	"p2.down":  {"s", "S"},
+	"p2.left":  {"a", "A"},
+	"p2.right": {"d", "D"},
+	"p3.up":    {"i", "I"},
+	"p3.down":  {"k", "K"},
+	"p3.left":  {"j", "J"},
+	"p3.right": {"l", "L"},
+	"p4.up":    {"8"},
+	"p4.down":  {"2"},
+	"p4.left":  {"4"},
+	"p4.right": {"6"},
+	"pause":    {"p", "P"},
+	"step":     {"n", "N"},
+	"restart":  {"Enter"},
+	"quit":     {"Esc"},
+	"controls": {"?"},
+}
+
+var keyNames = map[string]screen.Key{
+	"up":        screen.KeyArrowUp,
+	"down":      screen.KeyArrowDown,
+	"left":      screen.KeyArrowLeft,
+	"right":     screen.KeyArrowRight,
+	"enter":     screen.KeyEnter,
+	"esc":       screen.KeyEsc,
+	"space":     screen.KeySpace,
+	"tab":       screen.KeyTab,
+	"backspace": screen.KeyBackspace,
+	"insert":    screen.KeyInsert,
+	"delete":    screen.KeyDelete,
+	"home":      screen.KeyHome,
+	"end":       screen.KeyEnd,
+	"pgup":      screen.KeyPgup,
+	"pgdn":      screen.KeyPgdn,
+	"f1":        screen.KeyF1,
+	"f2":        screen.KeyF2,
+	"f3":        screen.KeyF3,
+	"f4":        screen.KeyF4,
+	"f5":        screen.KeyF5,
+	"f6":        screen.KeyF6,
+	"f7":        screen.KeyF7,
+	"f8":        screen.KeyF8,
+	"f9":        screen.KeyF9,
+	"f10":       screen.KeyF10,
+	"f11":       screen.KeyF11,
+	"f12":       screen.KeyF12,
+}
+
+// parseKey returns the rune of a single character key, or the screen key
+// of a named one with a zero rune.
+func parseKey(name string) (screen.Key, rune, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		ch, _ := utf8.DecodeRuneInString(name)
+		if ch == ' ' {
+			return screen.KeySpace, 0, nil
+		}
+		return 0, ch, nil
+	}
+	if key, ok := keyNames[strings.ToLower(name)]; ok {
+		return key, 0, nil
+	}
+	return 0, 0, fmt.Errorf("Unknown key: %q", name)
+}
+
+func DefaultControlsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-retro", "snake.json")
+}
+
+// LoadControls reads a JSON object mapping actions to lists of keys, e.g.
+// {"pause": ["Space"], "p2.up": ["t", "T"]}. Actions missing from the
+// file keep their default keys.
+func LoadControls(path string) (Controls, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overrides Controls
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	c := Controls{}
+	for action, keys := range DefaultControls {
+		c[action] = keys
+	}
+	for action, keys := range overrides {
+		c[action] = keys
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Validate checks that every action and key is known and that no key is
+// bound to more than one action.
+func (c Controls) Validate() error {
+	for action := range c {
+		if !isAction(action) {
+			return fmt.Errorf("Unknown action: %q", action)
+		}
+	}
+	bound := map[string]string{}
+	for _, action := range actions {
+		for _, name := range c[action] {
+			key, ch, err := parseKey(name)
+			if err != nil {
+				return fmt.Errorf("%s: %v", action, err)
+			}
+			id := fmt.Sprint(key, ch)
+			if other, ok := bound[id]; ok {
+				return fmt.Errorf("Key %q is bound to both %s and %s.", name, other, action)
+			}
+			bound[id] = action
+		}
+	}
+	return nil
+}
+
+func isAction(action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// bind makes every key of action call f.
+func (c Controls) bind(keyMap KeyMap, runeMap RuneMap, action string, f func()) {
+	for _, name := range c[action] {
+		// The controls were validated when they were loaded.
+		key, ch, _ := parseKey(name)
+		if ch != 0 {
+			runeMap[ch] = f
+		} else {
+			keyMap[key] = f
+		}
+	}
+}
+
+// describe lists the keys of action for the controls screen.
+func (c Controls) describe(action string) string {
+	return strings.Join(c[action], " ")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/dragonfi/go-retro/snake/screen"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeControls(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "snake.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaultControlsAreValid(t *testing.T) {
+	if err := DefaultControls.Validate(); err != nil {
+		t.Error("Default controls should be valid:", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	cases := []struct {
+		name string
+		key  screen.Key
+		ch   rune
+	}{
+		{"w", 0, 'w'},
+		{"?", 0, '?'},
+		{"Up", screen.KeyArrowUp, 0},
+		{"esc", screen.KeyEsc, 0},
+		{" ", screen.KeySpace, 0},
+		{"Space", screen.KeySpace, 0},
+	}
+	for _, c := range cases {
+		key, ch, err := parseKey(c.name)
+		if err != nil || key != c.key || ch != c.ch {
+			t.Error("Wrong key for:", c.name, "Expected:", c.key, c.ch, "Got:", key, ch, err)
+		}
+	}
+	if _, _, err := parseKey("Hyper"); err == nil {
+		t.Error("Unknown key names should be rejected.")
+	}
+}
+
+func TestLoadControlsKeepsDefaults(t *testing.T) {
+	c, err := LoadControls(writeControls(t, `{"pause": ["Space"], "p2.up": ["t", "T"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.describe("pause") != "Space" || c.describe("p2.up") != "t T" {
+		t.Error("Overrides should replace the default keys:", c["pause"], c["p2.up"])
+	}
+	if c.describe("p1.up") != "Up" {
+		t.Error("Other actions should keep their default keys:", c["p1.up"])
+	}
+}
+
+func TestLoadControlsReportsConflicts(t *testing.T) {
+	for _, content := range []string{
+		`{"pause": ["w"]}`,
+		`{"p1.up": ["Esc"]}`,
+		`{"p5.up": ["x"]}`,
+		`{"quit": ["Hyper"]}`,
+		`{"quit": "q"}`,
+	} {
+		if _, err := LoadControls(writeControls(t, content)); err == nil {
+			t.Error("Controls should be rejected:", content)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+// borderGlyphs are the characters of the frame around the arena.
+type borderGlyphs struct {
+	topLeft, topRight, bottomLeft, bottomRight rune
+	horizontal, vertical                       rune
+}
+
+// cellGlyph is how an arena cell is drawn: the first rune normally, both
+// runes side by side in wide mode.
+type cellGlyph [2]rune
+
+type glyphTheme struct {
+	border borderGlyphs
+	// wrapBorder marks the edges of arenas the snakes can wrap around.
+	wrapBorder borderGlyphs
+	wall       cellGlyph
+	body       cellGlyph
+	// bodies tell the snakes apart without colors, so none of them is
+	// used for walls or borders.
+	bodies [4]cellGlyph
+	dead   cellGlyph
+	// heads is indexed by the heading of the snake.
+	heads [4]cellGlyph
+}
+
+var glyphThemes = map[string]glyphTheme{
+	"ascii": {
+		border:     borderGlyphs{'#', '#', '#', '#', '#', '#'},
+		wrapBorder: borderGlyphs{'.', '.', '.', '.', '.', '.'},
+		wall:       cellGlyph{'#', '#'},
+		body:       cellGlyph{'#', '#'},
+		bodies:     [4]cellGlyph{{'@', '@'}, {'=', '='}, {'+', '+'}, {'%', '%'}},
+		dead:       cellGlyph{'X', 'X'},
+		heads:      [4]cellGlyph{{'O', 'O'}, {'O', 'O'}, {'O', 'O'}, {'O', 'O'}},
+	},
+	"box": {
+		border:     borderGlyphs{'┌', '┐', '└', '┘', '─', '│'},
+		wrapBorder: borderGlyphs{'┌', '┐', '└', '┘', '┄', '┆'},
+		wall:       cellGlyph{'▒', '▒'},
+		body:       cellGlyph{'■', ' '},
+		bodies:     [4]cellGlyph{{'■', ' '}, {'□', ' '}, {'●', ' '}, {'○', ' '}},
+		dead:       cellGlyph{'×', ' '},
+		heads:      [4]cellGlyph{{'▶', ' '}, {'▲', ' '}, {'◀', ' '}, {'▼', ' '}},
+	},
+	"block": {
+		border:     borderGlyphs{'█', '█', '█', '█', '█', '█'},
+		wrapBorder: borderGlyphs{'░', '░', '░', '░', '░', '░'},
+		wall:       cellGlyph{'▓', '▓'},
+		body:       cellGlyph{'█', '█'},
+		bodies:     [4]cellGlyph{{'▣', '▣'}, {'▞', '▞'}, {'▤', '▤'}, {'▦', '▦'}},
+		dead:       cellGlyph{'▒', '▒'},
+		heads:      [4]cellGlyph{{'→', '→'}, {'↑', '↑'}, {'←', '←'}, {'↓', '↓'}},
+	},
+}
+
+func (t glyphTheme) head(heading arena.Direction) cellGlyph {
+	return t.heads[heading]
+}
+
+// char returns the border character at column x and row y of a frame
+// around an area of the given size.
+func (b borderGlyphs) char(x, y, width, height int) rune {
+	switch {
+	case x == -1 && y == -1:
+		return b.topLeft
+	case x == width && y == -1:
+		return b.topRight
+	case x == -1 && y == height:
+		return b.bottomLeft
+	case x == width && y == height:
+		return b.bottomRight
+	case y == -1 || y == height:
+		return b.horizontal
+	}
+	return b.vertical
+}
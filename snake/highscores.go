@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/scores"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"io"
+	"sort"
+	"time"
+)
+
+const maxNameLength = 16
+
+// nameEntry prompts the players who made it into the high-score table for
+// their names, one after the other.
+type nameEntry struct {
+	players []int
+	name    []rune
+}
+
+// scoreKey separates the high scores of different kinds of games.
+func (w ArenaWidget) scoreKey() string {
+	mode := w.config.Goal
+	if w.config.Wrap {
+		mode += "+wrap"
+	}
+	if w.config.Items != "classic" {
+		mode += "+" + w.config.Items
+	}
+	return fmt.Sprintf("%s %dx%d %dp %db", mode, w.size.X, w.size.Y, w.config.Players, w.config.Bots)
+}
+
+func (w *ArenaWidget) startNameEntry() {
+	if w.config.Scores == nil || w.recorder == nil {
+		return
+	}
+	players := []int{}
+	for i := 0; i < w.config.Players; i++ {
+		if ok, _ := w.config.Scores.Qualifies(w.scoreKey(), w.state.Snakes[i].Score); ok {
+			players = append(players, i)
+		}
+	}
+	if len(players) > 0 {
+		w.entry = &nameEntry{players: players}
+	}
+}
+
+func (w *ArenaWidget) handleNameEntry(ev screen.Event) {
+	e := w.entry
+	switch {
+	case ev.Key == screen.KeyEnter:
+		w.saveScore()
+		w.nextName()
+	case ev.Key == screen.KeyEsc:
+		w.nextName()
+	case ev.Key == screen.KeyBackspace:
+		if len(e.name) > 0 {
+			e.name = e.name[:len(e.name)-1]
+		}
+	case len(e.name) >= maxNameLength:
+	case ev.Key == screen.KeySpace:
+		e.name = append(e.name, ' ')
+	case ev.Ch != 0:
+		e.name = append(e.name, ev.Ch)
+	}
+}
+
+func (w *ArenaWidget) saveScore() {
+	player := w.entry.players[0]
+	name := string(w.entry.name)
+	if name == "" {
+		name = w.snakeName(player)
+	}
+	entry := scores.Entry{Name: name, Score: w.state.Snakes[player].Score, Time: time.Now()}
+	rank, err := w.config.Scores.Add(w.scoreKey(), entry)
+	switch {
+	case err != nil:
+		w.scoreMessage = fmt.Sprint("Cannot save the high score: ", err)
+	case rank != -1:
+		w.scoreMessage = fmt.Sprintf("%s is #%d in the high scores!", name, rank+1)
+	}
+}
+
+func (w *ArenaWidget) nextName() {
+	w.entry.players = w.entry.players[1:]
+	w.entry.name = nil
+	if len(w.entry.players) == 0 {
+		w.entry = nil
+	}
+}
+
+// nameEntryText is the name prompt, or the outcome of the last entry.
+func (w ArenaWidget) nameEntryText() string {
+	if w.entry != nil {
+		return fmt.Sprintf("New high score for %s! Name: %s_",
+			w.snakeName(w.entry.players[0]), string(w.entry.name))
+	}
+	return w.scoreMessage
+}
+
+func printScores(out io.Writer, t scores.Table) {
+	keys := make([]string, 0, len(t))
+	for key := range t {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintln(out, key)
+		for i, e := range t[key] {
+			fmt.Fprintf(out, "%3d. %-*s %6d  %s\n", i+1, maxNameLength, e.Name, e.Score, e.Time.Format("2006-01-02"))
+		}
+		fmt.Fprintln(out)
+	}
+}
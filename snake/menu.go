@@ -0,0 +1,229 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/arena"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// setupFlags are the flags that can also be set in the menu. Setting any
+// of them on the command line skips the menu.
+var setupFlags = []string{"p", "bots", "bot-kind", "speed", "map", "wrap", "rules", "items"}
+
+type menuItem struct {
+	label   string
+	options []string
+	current int
+}
+
+func (i menuItem) value() string {
+	return i.options[i.current]
+}
+
+// choose selects option if it is one of the options of the item.
+func (i *menuItem) choose(option string) {
+	for j, o := range i.options {
+		if o == option {
+			i.current = j
+		}
+	}
+}
+
+func (i *menuItem) change(delta int) {
+	i.current = (i.current + delta + len(i.options)) % len(i.options)
+}
+
+// MenuWidget is the game setup screen shown before a game starts.
+type MenuWidget struct {
+	screen   screen.Screen
+	offset   Position
+	config   Config
+	levels   map[string]arena.Level
+	items    []*menuItem
+	selected int
+	message  string
+	running  bool
+	start    bool
+	KeyMap   KeyMap
+	RuneMap  RuneMap
+}
+
+//go:embed maps/*.txt
+var bundledMaps embed.FS
+
+// BundledMaps are the maps built into the binary, offered in the menu
+// unless -maps is given.
+func BundledMaps() fs.FS {
+	maps, err := fs.Sub(bundledMaps, "maps")
+	if err != nil {
+		panic(err)
+	}
+	return maps
+}
+
+// LoadLevels loads every map in maps, keyed by file name without extension.
+func LoadLevels(maps fs.FS) (map[string]arena.Level, error) {
+	if _, err := fs.Stat(maps, "."); err != nil {
+		return nil, err
+	}
+	paths, err := fs.Glob(maps, "*.txt")
+	if err != nil {
+		return nil, err
+	}
+	levels := map[string]arena.Level{}
+	for _, p := range paths {
+		level, err := loadLevel(maps, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", p, err)
+		}
+		levels[strings.TrimSuffix(path.Base(p), ".txt")] = level
+	}
+	return levels, nil
+}
+
+func loadLevel(maps fs.FS, name string) (arena.Level, error) {
+	f, err := maps.Open(name)
+	if err != nil {
+		return arena.Level{}, err
+	}
+	defer f.Close()
+	return arena.ParseLevel(f)
+}
+
+// speedNames lists the speed presets from the slowest.
+func speedNames() []string {
+	names := []string{}
+	for name := range speedPresets {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return speedPresets[names[i]] < speedPresets[names[j]] })
+	return names
+}
+
+func NewMenuWidget(s screen.Screen, ox, oy int, config Config, levels map[string]arena.Level) *MenuWidget {
+	maps := []string{"open"}
+	for name := range levels {
+		maps = append(maps, name)
+	}
+	sort.Strings(maps[1:])
+	goals := []string{}
+	for name := range arena.Goals {
+		goals = append(goals, name)
+	}
+	sort.Slice(goals, func(i, j int) bool { return arena.Goals[goals[i]] < arena.Goals[goals[j]] })
+	items := []string{}
+	for name := range arena.SpawnTables {
+		items = append(items, name)
+	}
+	sort.Strings(items)
+
+	m := MenuWidget{screen: s, offset: Position{ox, oy}, config: config, levels: levels}
+	m.items = []*menuItem{
+		{label: "Players", options: []string{"1", "2", "3", "4"}},
+		{label: "Bots", options: []string{"0", "1", "2", "3"}},
+		{label: "Bot kind", options: []string{"random-safe", "greedy", "bfs", "flood"}},
+		{label: "Speed", options: speedNames()},
+		{label: "Map", options: maps},
+		{label: "Wrap", options: []string{"off", "on"}},
+		{label: "Rules", options: goals},
+		{label: "Items", options: items},
+	}
+	m.item("Players").choose(strconv.Itoa(config.Players))
+	m.item("Bots").choose(strconv.Itoa(config.Bots))
+	m.item("Bot kind").choose(config.BotKind)
+	m.item("Speed").choose("normal")
+	for name, speed := range speedPresets {
+		if speed == config.Speed {
+			m.item("Speed").choose(name)
+		}
+	}
+	if config.Wrap {
+		m.item("Wrap").choose("on")
+	}
+	m.item("Rules").choose(config.Goal)
+	m.item("Items").choose(config.Items)
+	m.setDefaultMap()
+	return &m
+}
+
+func (m *MenuWidget) item(label string) *menuItem {
+	for _, i := range m.items {
+		if i.label == label {
+			return i
+		}
+	}
+	panic("Unknown menu item: " + label)
+}
+
+// Config returns the configuration chosen in the menu, the settings not
+// in the menu are those the menu was created with.
+func (m *MenuWidget) Config() Config {
+	c := m.config
+	c.Players, _ = strconv.Atoi(m.item("Players").value())
+	c.Bots, _ = strconv.Atoi(m.item("Bots").value())
+	c.BotKind = m.item("Bot kind").value()
+	c.Speed = speedPresets[m.item("Speed").value()]
+	c.Level = nil
+	if level, ok := m.levels[m.item("Map").value()]; ok {
+		c.Level = &level
+	}
+	c.Wrap = m.item("Wrap").value() == "on"
+	c.Goal = m.item("Rules").value()
+	c.Items = m.item("Items").value()
+	return c
+}
+
+func (m *MenuWidget) setDefaultMap() {
+	m.KeyMap = KeyMap{}
+	m.RuneMap = RuneMap{}
+
+	m.KeyMap[screen.KeyEsc] = func() { m.running = false }
+	m.KeyMap[screen.KeyEnter] = func() { m.startGame() }
+	m.KeyMap[screen.KeyArrowUp] = func() { m.selected = (m.selected + len(m.items) - 1) % len(m.items) }
+	m.KeyMap[screen.KeyArrowDown] = func() { m.selected = (m.selected + 1) % len(m.items) }
+	m.KeyMap[screen.KeyArrowLeft] = func() { m.items[m.selected].change(-1) }
+	m.KeyMap[screen.KeyArrowRight] = func() { m.items[m.selected].change(1) }
+}
+
+func (m *MenuWidget) startGame() {
+	if err := m.Config().Validate(); err != nil {
+		m.message = err.Error()
+		return
+	}
+	m.start = true
+	m.running = false
+}
+
+func (m MenuWidget) Draw() {
+	putString(m.screen, m.offset.X, m.offset.Y, "Snake")
+	for i, item := range m.items {
+		cursor := " "
+		if i == m.selected {
+			cursor = ">"
+		}
+		putString(m.screen, m.offset.X, m.offset.Y+2+i, fmt.Sprintf("%s %-10s < %s >", cursor, item.label, item.value()))
+	}
+	y := m.offset.Y + 3 + len(m.items)
+	putString(m.screen, m.offset.X, y, "Up/Down: Select  Left/Right: Change  Enter: Start  ESC: Exit")
+	putString(m.screen, m.offset.X, y+2, m.message)
+}
+
+// Run shows the menu until a game is started, which returns true, or the
+// menu is left.
+func (m *MenuWidget) Run() bool {
+	m.running, m.start = true, false
+	for m.running {
+		m.screen.Clear()
+		m.Draw()
+		m.screen.Flush()
+		m.message = ""
+		handleEvent(<-m.screen.Events(), m.KeyMap, m.RuneMap)
+	}
+	return m.start
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/dragonfi/go-retro/snake/arena"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"os"
+	"testing"
+)
+
+func testConfig() Config {
+	return Config{Players: 1, BotKind: "flood", BotSpeed: 100, Items: "classic", MaxItems: 1,
+		Goal: "survival", Length: 20, TimeLimit: 600, Rounds: 1, Speed: defaultSpeed,
+		Glyphs: "ascii", Theme: "classic"}
+}
+
+func TestMenuStartsWithFlagValues(t *testing.T) {
+	c := testConfig()
+	c.Players, c.Bots, c.Wrap, c.Goal, c.Speed = 2, 1, true, "timed", speedPresets["fast"]
+	m := NewMenuWidget(screen.NewMemory(80, 24), 0, 0, c, nil)
+	got := m.Config()
+	if got.Players != 2 || got.Bots != 1 || !got.Wrap || got.Goal != "timed" || got.Speed != c.Speed {
+		t.Error("Menu should start with the given config:", got)
+	}
+}
+
+func TestMenuChangesConfig(t *testing.T) {
+	levels := map[string]arena.Level{"cross": arena.DefaultLevel(30, 15)}
+	m := NewMenuWidget(screen.NewMemory(80, 24), 0, 0, testConfig(), levels)
+	m.item("Players").change(1)
+	m.item("Bots").change(-1)
+	m.item("Map").change(1)
+	m.item("Rules").choose("length")
+	c := m.Config()
+	if c.Players != 2 || c.Bots != 3 || c.Level == nil || c.Goal != "length" {
+		t.Error("Wrong config from menu:", c)
+	}
+	if c.MaxItems != 1 || c.Rounds != 1 {
+		t.Error("Settings missing from the menu should be kept:", c)
+	}
+}
+
+func TestMenuRejectsInvalidConfig(t *testing.T) {
+	m := NewMenuWidget(screen.NewMemory(80, 24), 0, 0, testConfig(), nil)
+	m.item("Players").choose("4")
+	m.item("Bots").choose("1")
+	m.startGame()
+	if m.start || m.message == "" {
+		t.Error("Starting with too many snakes should show an error.")
+	}
+}
+
+func TestLoadLevels(t *testing.T) {
+	levels, err := LoadLevels(BundledMaps())
+	if err != nil || len(levels) == 0 {
+		t.Error("The bundled maps should load:", len(levels), err)
+	}
+	if _, err := LoadLevels(os.DirFS("no-such-maps")); !os.IsNotExist(err) {
+		t.Error("A missing directory should be reported:", err)
+	}
+}
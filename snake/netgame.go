@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/arena"
+	"github.com/dragonfi/go-retro/snake/netplay"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"net"
+)
+
+func NewClientWidget(s screen.Screen, c *netplay.Client, config Config) *ArenaWidget {
+	state := c.State()
+	// The server sets the pace, the client only has to redraw often enough
+	// to show every update.
+	display := config.display()
+	display.Players, display.Speed, display.Controls = c.Players(), maxSpeed, config.Controls
+	display.Bots = len(state.Snakes) - display.Players
+	w := ArenaWidget{
+		arena:  c,
+		remote: c,
+		screen: s,
+		size:   Position{state.Size.X, state.Size.Y},
+		state:  state,
+		config: display,
+	}
+	w.setClientMap()
+	return &w
+}
+
+func (w *ArenaWidget) setClientMap() {
+	w.setDefaultMap()
+	w.bind("restart", func() { w.remote.Restart() })
+	// The server steers the snake assigned to this client whatever index
+	// is given, so the first player's keys are enough.
+	w.addPlayerMap(0)
+}
+
+func serve(addr string, c Config, width, height int) error {
+	level := arena.DefaultLevel(width, height)
+	if c.Level != nil {
+		level = *c.Level
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s, err := netplay.NewServer(l, netplay.ServerConfig{
+		Level:    level,
+		Players:  c.Players,
+		Bots:     c.Bots,
+		BotKind:  c.BotKind,
+		BotSpeed: c.BotSpeed,
+		Options:  c.Options(c.Seed),
+		Interval: interval(c.Speed),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Waiting for %d players on %s\n", c.Players, s.Addr())
+	return s.Serve()
+}
+
+func connect(addr string, config Config) error {
+	c, err := netplay.Dial(addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	s, err := screen.NewTermbox(config.Colors)
+	if err != nil {
+		return err
+	}
+	aw := NewClientWidget(s, c, config)
+	aw.Run()
+	s.Close()
+	return c.Err()
+}
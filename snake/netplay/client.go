@@ -0,0 +1,130 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+// Client is an arena.Arena backed by a server: State returns the latest
+// snapshot received and SetSnakeHeading steers the snake the server
+// assigned to this client, whatever index is passed.
+type Client struct {
+	conn    net.Conn
+	enc     *json.Encoder
+	mu      sync.Mutex
+	snake   int
+	players int
+	state   arena.State
+	events  []arena.Event
+	err     error
+	// writeMu serializes commands apart from mu, so that a stalled write
+	// does not hold up the updates read.
+	writeMu sync.Mutex
+}
+
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(conn)
+	var u update
+	if err := dec.Decode(&u); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &Client{conn: conn, enc: json.NewEncoder(conn), snake: u.Snake, players: u.Players, state: u.State}
+	go c.read(dec)
+	return c, nil
+}
+
+func (c *Client) read(dec *json.Decoder) {
+	for {
+		var u update
+		err := dec.Decode(&u)
+		c.mu.Lock()
+		if err != nil {
+			c.err = err
+			c.mu.Unlock()
+			return
+		}
+		c.snake, c.players, c.state = u.Snake, u.Players, u.State
+		c.events = append(c.events, u.Events...)
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) send(cmd command) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.Err() != nil {
+		return
+	}
+	if err := c.enc.Encode(cmd); err != nil {
+		c.mu.Lock()
+		if c.err == nil {
+			c.err = err
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *Client) State() arena.State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.Copy()
+}
+
+// Tick does not advance the game, the server does: it returns the events
+// received since the previous call.
+func (c *Client) Tick() []arena.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	events := c.events
+	c.events = nil
+	return events
+}
+
+func (c *Client) SetSnakeHeading(snake int, h arena.Direction) {
+	c.send(command{Heading: &h})
+}
+
+// SetSnakeSpeed does nothing: only the server decides the speed of snakes.
+func (c *Client) SetSnakeSpeed(snake int, speed int) {
+}
+
+func (c *Client) AddSnake(x, y, size int, h arena.Direction) (int, error) {
+	return -1, errors.New("Snakes cannot be added by clients.")
+}
+
+func (c *Client) Restart() {
+	c.send(command{Restart: true})
+}
+
+func (c *Client) Snake() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snake
+}
+
+// Players is the number of snakes steered by clients, the others are bots.
+func (c *Client) Players() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.players
+}
+
+// Err returns the error that ended the connection, if any.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,156 @@
+package netplay
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+func startServer(t *testing.T, c ServerConfig) *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(l, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func dial(t *testing.T, s *Server) *Client {
+	c, err := Dial(s.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for:", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClientsGetTheirOwnSnakes(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 2, Options: arena.Options{Seed: 1}, Interval: 10 * time.Millisecond})
+	c1, c2 := dial(t, s), dial(t, s)
+	if c1.Snake() != 0 || c2.Snake() != 1 {
+		t.Error("Wrong snake indices:", c1.Snake(), c2.Snake())
+	}
+	if len(c1.State().Snakes) != 2 {
+		t.Error("Clients should see all snakes.")
+	}
+}
+
+func TestGameWaitsForAllPlayers(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 2, Options: arena.Options{Seed: 1}, Interval: 5 * time.Millisecond})
+	c1 := dial(t, s)
+	start := c1.State().Snakes[0].Head()
+	time.Sleep(50 * time.Millisecond)
+	if c1.State().Snakes[0].Head() != start {
+		t.Error("Game should not start before all players joined.")
+	}
+	dial(t, s)
+	waitFor(t, "the game to start", func() bool { return c1.State().Snakes[0].Head() != start })
+}
+
+func TestHeadingChangesAreBroadcast(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 2, Options: arena.Options{Seed: 1}, Interval: 10 * time.Millisecond})
+	c1, c2 := dial(t, s), dial(t, s)
+	// The snake index is ignored: clients can only steer their own snake.
+	c2.SetSnakeHeading(0, arena.NORTH)
+	waitFor(t, "the heading change", func() bool {
+		snakes := c1.State().Snakes
+		return snakes[1].Heading == arena.NORTH && snakes[0].Heading == arena.EAST
+	})
+}
+
+func TestRestartAfterGameOver(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(12, 6), Players: 1, Options: arena.Options{Seed: 1}, Interval: 5 * time.Millisecond})
+	c := dial(t, s)
+	waitFor(t, "game over", func() bool { return c.State().GameIsOver })
+	c.Restart()
+	waitFor(t, "restart", func() bool { return !c.State().GameIsOver })
+}
+
+func TestExtraClientsAreRejected(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 1, Options: arena.Options{Seed: 1}, Interval: 10 * time.Millisecond})
+	dial(t, s)
+	if _, err := Dial(s.Addr().String()); err == nil {
+		t.Error("Server should reject clients when all slots are taken.")
+	}
+}
+
+func TestClientReceivesEvents(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(12, 6), Players: 1, Options: arena.Options{Seed: 1}, Interval: 5 * time.Millisecond})
+	c := dial(t, s)
+	died := false
+	waitFor(t, "the death of the snake", func() bool {
+		for _, e := range c.Tick() {
+			if e.Kind == arena.SNAKE_DIED && e.Snake == 0 && e.Cause == arena.HIT_WALL {
+				died = true
+			}
+		}
+		return died
+	})
+}
+
+func TestClientsKnowWhichSnakesAreBots(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 1, Bots: 2, BotKind: "greedy", Options: arena.Options{Seed: 1}, Interval: 10 * time.Millisecond})
+	c := dial(t, s)
+	if c.Players() != 1 || len(c.State().Snakes) != 3 {
+		t.Error("Expected 1 player and 3 snakes. Got:", c.Players(), len(c.State().Snakes))
+	}
+}
+
+func TestLeftSlotsCanBeRejoined(t *testing.T) {
+	s := startServer(t, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 2, Options: arena.Options{Seed: 1}, Interval: 10 * time.Millisecond})
+	c1, c2 := dial(t, s), dial(t, s)
+	c1.Close()
+	waitFor(t, "the slot to be freed", func() bool {
+		c, err := Dial(s.Addr().String())
+		if err != nil {
+			return false
+		}
+		defer c.Close()
+		if c.Snake() != 0 {
+			t.Fatal("Rejoining client should take over the free snake, got:", c.Snake())
+		}
+		return true
+	})
+	if c2.Err() != nil {
+		t.Error("Remaining client should stay connected:", c2.Err())
+	}
+}
+
+func TestClientsFallingBehindAreDropped(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(l, ServerConfig{Level: arena.DefaultLevel(40, 20), Players: 1, Options: arena.Options{Seed: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	// The client is never started, so nothing drains its updates.
+	conn, _ := net.Pipe()
+	c := &client{conn: conn, out: make(chan update, sendBuffer)}
+	s.clients[0] = c
+	for i := 0; i <= sendBuffer; i++ {
+		s.broadcast()
+	}
+	if s.clients[0] != nil {
+		t.Error("Server should drop clients that fall behind.")
+	}
+}
@@ -0,0 +1,288 @@
+package netplay
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+const (
+	writeTimeout = time.Second
+	// sendBuffer is the number of updates a client can fall behind before
+	// it is disconnected.
+	sendBuffer = 16
+)
+
+// update is sent by the server after every tick. Snake is the index of the
+// snake controlled by the receiving client, the snakes before Players are
+// steered by clients and the rest by bots. Events are those of the ticks
+// since the previous update.
+type update struct {
+	Snake   int
+	Players int
+	State   arena.State
+	Events  []arena.Event `json:",omitempty"`
+}
+
+// command is sent by clients; the server ignores headings for any snake
+// other than the one it assigned to the connection.
+type command struct {
+	Heading *arena.Direction `json:",omitempty"`
+	Restart bool             `json:",omitempty"`
+	snake   int
+}
+
+type ServerConfig struct {
+	Level   arena.Level
+	Players int
+	Bots    int
+	BotKind string
+	// BotSpeed is in percent of arena.NormalSpeed, normal if 0.
+	BotSpeed int
+	// Options.Seed is used for every game unless it is 0.
+	Options  arena.Options
+	Interval time.Duration
+}
+
+// client is a connection to the player of snake. Updates are written by a
+// goroutine of its own, so a slow connection does not hold up the game.
+type client struct {
+	conn  net.Conn
+	snake int
+	out   chan update
+}
+
+func (c *client) write() {
+	enc := json.NewEncoder(c.conn)
+	for u := range c.out {
+		c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := enc.Encode(u); err != nil {
+			// Closing the connection ends read, which frees the slot.
+			c.conn.Close()
+			return
+		}
+	}
+}
+
+// Server owns the arena: it ticks it, applies the heading changes sent by
+// clients and broadcasts the resulting state to every client.
+type Server struct {
+	config   ServerConfig
+	listener net.Listener
+	arena    arena.Arena
+	bots     []arena.Controller
+	events   []arena.Event
+	// clients is indexed by snake, nil for the slots nobody took or left.
+	clients  []*client
+	started  bool
+	joins    chan net.Conn
+	leaves   chan *client
+	commands chan command
+	done     chan struct{}
+}
+
+func NewServer(l net.Listener, c ServerConfig) (*Server, error) {
+	s := &Server{
+		config:   c,
+		listener: l,
+		clients:  make([]*client, c.Players),
+		joins:    make(chan net.Conn),
+		leaves:   make(chan *client),
+		commands: make(chan command),
+		done:     make(chan struct{}),
+	}
+	if err := s.reset(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) reset() error {
+	o := s.config.Options
+	if o.Seed == 0 {
+		o.Seed = time.Now().UnixNano()
+	}
+	snakes := s.config.Players + s.config.Bots
+	if snakes > len(s.config.Level.Spawns) {
+		return errors.New("The level has not enough spawn points.")
+	}
+	s.arena = arena.NewWithOptions(s.config.Level.Options(o))
+	s.bots = nil
+	s.events = nil
+	for i, spawn := range s.config.Level.Spawns[:snakes] {
+		if i >= s.config.Players {
+			bot, err := arena.NewController(s.config.BotKind, o.Seed+int64(i))
+			if err != nil {
+				return err
+			}
+			s.bots = append(s.bots, bot)
+		}
+		if _, err := s.arena.AddSnake(spawn.X, spawn.Y, spawn.Size, spawn.Heading); err != nil {
+			return err
+		}
+		if i >= s.config.Players && s.config.BotSpeed != 0 {
+			s.arena.SetSnakeSpeed(i, s.config.BotSpeed)
+		}
+	}
+	return nil
+}
+
+// Serve accepts clients until the listener is closed. The game starts
+// ticking once all player slots are taken and goes on when players leave.
+func (s *Server) Serve() error {
+	go s.run()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+				return err
+			}
+		}
+		select {
+		case s.joins <- conn:
+		case <-s.done:
+			conn.Close()
+			return nil
+		}
+	}
+}
+
+func (s *Server) Close() error {
+	close(s.done)
+	return s.listener.Close()
+}
+
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+func (s *Server) run() {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case conn := <-s.joins:
+			s.join(conn)
+		case c := <-s.leaves:
+			s.drop(c)
+		case cmd := <-s.commands:
+			s.apply(cmd)
+		case <-ticker.C:
+			s.started = s.started || s.connected() == s.config.Players
+			if s.started {
+				s.tick()
+			}
+			s.broadcast()
+		case <-s.done:
+			for _, c := range s.clients {
+				if c != nil {
+					s.drop(c)
+				}
+			}
+			return
+		}
+	}
+}
+
+func (s *Server) connected() int {
+	connected := 0
+	for _, c := range s.clients {
+		if c != nil {
+			connected++
+		}
+	}
+	return connected
+}
+
+// join gives conn the first free slot. Once the game started, a player
+// joining a slot that was left takes over its snake.
+func (s *Server) join(conn net.Conn) {
+	for snake, c := range s.clients {
+		if c == nil {
+			c = &client{conn: conn, snake: snake, out: make(chan update, sendBuffer)}
+			s.clients[snake] = c
+			go c.write()
+			go s.read(c)
+			s.send(c, s.arena.State(), nil)
+			return
+		}
+	}
+	conn.Close()
+}
+
+// drop disconnects c and frees its slot. The snake of a disconnected
+// client keeps moving until it dies.
+func (s *Server) drop(c *client) {
+	if s.clients[c.snake] != c {
+		return
+	}
+	s.clients[c.snake] = nil
+	close(c.out)
+	c.conn.Close()
+}
+
+func (s *Server) read(c *client) {
+	dec := json.NewDecoder(c.conn)
+	for {
+		var cmd command
+		if err := dec.Decode(&cmd); err != nil {
+			select {
+			case s.leaves <- c:
+			case <-s.done:
+			}
+			return
+		}
+		cmd.snake = c.snake
+		select {
+		case s.commands <- cmd:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Server) apply(cmd command) {
+	if cmd.Heading != nil {
+		s.arena.SetSnakeHeading(cmd.snake, *cmd.Heading)
+	}
+	if cmd.Restart && s.arena.State().GameIsOver {
+		// The configuration was already validated by NewServer.
+		s.reset()
+	}
+}
+
+func (s *Server) tick() {
+	state := s.arena.State()
+	for i, bot := range s.bots {
+		snake := s.config.Players + i
+		if state.WillMove(snake) {
+			s.arena.SetSnakeHeading(snake, bot.Heading(state, snake))
+		}
+	}
+	s.events = append(s.events, s.arena.Tick()...)
+}
+
+func (s *Server) broadcast() {
+	state := s.arena.State()
+	for _, c := range s.clients {
+		if c != nil {
+			s.send(c, state, s.events)
+		}
+	}
+	s.events = nil
+}
+
+// send queues an update for c, dropping clients that fell too far behind.
+func (s *Server) send(c *client, state arena.State, events []arena.Event) {
+	select {
+	case c.out <- update{Snake: c.snake, Players: s.config.Players, State: state, Events: events}:
+	default:
+		s.drop(c)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/replay"
+	"github.com/dragonfi/go-retro/snake/screen"
+)
+
+const (
+	fastForwardTicks = 4
+	rewindTicks      = 20
+)
+
+// NewReplayWidget plays rec back on s, with the display settings of config.
+func NewReplayWidget(s screen.Screen, rec replay.Record, config Config) (*ArenaWidget, error) {
+	p, err := replay.NewPlayer(rec)
+	if err != nil {
+		return nil, err
+	}
+	config = config.display()
+	config.Speed = defaultSpeed
+	w := ArenaWidget{
+		arena:  p,
+		player: p,
+		screen: s,
+		size:   Position{rec.Options.Width, rec.Options.Height},
+		config: config,
+		state:  p.State(),
+	}
+	w.setReplayMap()
+	return &w, nil
+}
+
+func (w *ArenaWidget) seek(ticks int) {
+	w.player.Seek(w.player.Position() + ticks)
+	w.lastDeath = ""
+	w.state = w.player.State()
+}
+
+func (w *ArenaWidget) step(ticks int) {
+	w.paused = true
+	w.seek(ticks)
+}
+
+func (w *ArenaWidget) setReplayMap() {
+	w.KeyMap = KeyMap{}
+	w.RuneMap = RuneMap{}
+
+	w.KeyMap[screen.KeyEsc] = func() { w.Exit() }
+	w.KeyMap[screen.KeyEnter] = func() { w.seek(-w.player.Position()) }
+	w.KeyMap[screen.KeySpace] = func() { w.paused = !w.paused }
+	w.KeyMap[screen.KeyArrowRight] = func() { w.step(1) }
+	w.KeyMap[screen.KeyArrowLeft] = func() { w.step(-1) }
+	w.RuneMap['f'] = func() { w.fastForward = !w.fastForward }
+	w.RuneMap['F'] = func() { w.fastForward = !w.fastForward }
+	w.RuneMap['r'] = func() { w.seek(-rewindTicks) }
+	w.RuneMap['R'] = func() { w.seek(-rewindTicks) }
+}
+
+func (w ArenaWidget) putReplayStatus() {
+	status := fmt.Sprintf("Replay: %d/%d", w.player.Position(), w.player.Len())
+	if w.paused {
+		status += " [paused]"
+	}
+	if w.fastForward {
+		status += fmt.Sprintf(" [x%d]", fastForwardTicks)
+	}
+	w.putString(0, w.hudTop(), status)
+	w.putString(0, w.state.Size.Y+1, "Space: Pause  Left/Right: Step  R: Rewind  F: Fast forward  Enter: Restart  ESC: Exit")
+}
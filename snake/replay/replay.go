@@ -0,0 +1,212 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+// Input is a SetSnakeHeading call issued before the given tick, or a
+// SetSnakeSpeed call if Speed is set.
+type Input struct {
+	Tick    int
+	Snake   int
+	Heading arena.Direction
+	Speed   int `json:",omitempty"`
+}
+
+// Record holds everything needed to replay a game: the arena options, the
+// snakes added before the first tick and every heading and speed change.
+type Record struct {
+	Options arena.Options
+	Spawns  []arena.Spawn
+	Inputs  []Input
+	Ticks   int
+}
+
+func (r Record) Copy() Record {
+	spawns := make([]arena.Spawn, len(r.Spawns))
+	copy(spawns, r.Spawns)
+	inputs := make([]Input, len(r.Inputs))
+	copy(inputs, r.Inputs)
+	return Record{Options: r.Options, Spawns: spawns, Inputs: inputs, Ticks: r.Ticks}
+}
+
+func (r Record) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+func Load(r io.Reader) (Record, error) {
+	var rec Record
+	if err := json.NewDecoder(r).Decode(&rec); err != nil {
+		return Record{}, err
+	}
+	return rec, nil
+}
+
+func SaveFile(path string, r Record) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func LoadFile(path string) (Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Record{}, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Recorder is an arena.Arena that records every call made to the arena
+// it wraps.
+type Recorder struct {
+	arena  arena.Arena
+	record Record
+}
+
+func NewRecorder(o arena.Options) *Recorder {
+	return &Recorder{arena: arena.NewWithOptions(o), record: Record{Options: o}}
+}
+
+func (r *Recorder) State() arena.State {
+	return r.arena.State()
+}
+
+// Tick only counts the ticks that move the game, so waiting on the game
+// over screen does not make the record longer.
+func (r *Recorder) Tick() []arena.Event {
+	if r.arena.State().GameIsOver {
+		return nil
+	}
+	r.record.Ticks++
+	return r.arena.Tick()
+}
+
+// SetSnakeHeading only records the calls that queue a turn. Bots ask for
+// a heading every move, mostly the one the snake already has.
+func (r *Recorder) SetSnakeHeading(snake int, h arena.Direction) {
+	queued := r.arena.State().Snakes[snake].QueuedTurns()
+	r.arena.SetSnakeHeading(snake, h)
+	if r.arena.State().Snakes[snake].QueuedTurns() != queued {
+		r.record.Inputs = append(r.record.Inputs, Input{Tick: r.record.Ticks, Snake: snake, Heading: h})
+	}
+}
+
+func (r *Recorder) SetSnakeSpeed(snake int, speed int) {
+	r.record.Inputs = append(r.record.Inputs, Input{Tick: r.record.Ticks, Snake: snake, Speed: speed})
+	r.arena.SetSnakeSpeed(snake, speed)
+}
+
+func (r *Recorder) AddSnake(x, y, size int, h arena.Direction) (int, error) {
+	if r.record.Ticks != 0 {
+		return -1, errors.New("Snakes can only be recorded before the first tick.")
+	}
+	snake, err := r.arena.AddSnake(x, y, size, h)
+	if err == nil {
+		r.record.Spawns = append(r.record.Spawns, arena.Spawn{Position: arena.Position{X: x, Y: y}, Size: size, Heading: h})
+	}
+	return snake, err
+}
+
+func (r *Recorder) Record() Record {
+	return r.record.Copy()
+}
+
+// Player is a read-only arena.Arena that plays back a Record. Seeking
+// backwards re-simulates the game from the start, which is exact because
+// the arena is deterministic for a given seed and input sequence.
+type Player struct {
+	record Record
+	arena  arena.Arena
+	tick   int
+	next   int
+}
+
+func NewPlayer(r Record) (*Player, error) {
+	p := &Player{record: r.Copy()}
+	if err := p.restart(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Player) restart() error {
+	p.arena = arena.NewWithOptions(p.record.Options)
+	p.tick, p.next = 0, 0
+	for _, s := range p.record.Spawns {
+		if _, err := p.arena.AddSnake(s.X, s.Y, s.Size, s.Heading); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Player) State() arena.State {
+	return p.arena.State()
+}
+
+func (p *Player) Tick() []arena.Event {
+	if p.Done() {
+		return nil
+	}
+	for p.next < len(p.record.Inputs) && p.record.Inputs[p.next].Tick <= p.tick {
+		in := p.record.Inputs[p.next]
+		if in.Speed != 0 {
+			p.arena.SetSnakeSpeed(in.Snake, in.Speed)
+		} else {
+			p.arena.SetSnakeHeading(in.Snake, in.Heading)
+		}
+		p.next++
+	}
+	p.tick++
+	return p.arena.Tick()
+}
+
+func (p *Player) SetSnakeHeading(snake int, h arena.Direction) {
+}
+
+func (p *Player) SetSnakeSpeed(snake int, speed int) {
+}
+
+func (p *Player) AddSnake(x, y, size int, h arena.Direction) (int, error) {
+	return -1, errors.New("Snakes cannot be added during playback.")
+}
+
+func (p *Player) Seek(tick int) {
+	if tick < 0 {
+		tick = 0
+	}
+	if tick > p.record.Ticks {
+		tick = p.record.Ticks
+	}
+	if tick < p.tick {
+		// The spawns were already validated by NewPlayer.
+		p.restart()
+	}
+	for p.tick < tick {
+		p.Tick()
+	}
+}
+
+func (p *Player) Position() int {
+	return p.tick
+}
+
+func (p *Player) Len() int {
+	return p.record.Ticks
+}
+
+func (p *Player) Done() bool {
+	return p.tick >= p.record.Ticks
+}
@@ -0,0 +1,169 @@
+package replay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dragonfi/go-retro/snake/arena"
+)
+
+func recordGame(t *testing.T) (Record, []arena.State) {
+	r := NewRecorder(arena.Options{Width: 40, Height: 20, Seed: 7})
+	if _, err := r.AddSnake(10, 5, 5, arena.EAST); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AddSnake(10, 15, 5, arena.EAST); err != nil {
+		t.Fatal(err)
+	}
+	turns := map[int][2]arena.Direction{
+		2: {arena.NORTH, arena.SOUTH},
+		4: {arena.EAST, arena.EAST},
+		9: {arena.SOUTH, arena.NORTH},
+	}
+	states := []arena.State{r.State()}
+	for i := 0; i < 15; i++ {
+		if d, ok := turns[i]; ok {
+			r.SetSnakeHeading(0, d[0])
+			r.SetSnakeHeading(1, d[1])
+		}
+		r.Tick()
+		states = append(states, r.State())
+	}
+	return r.Record(), states
+}
+
+func assertStatesMatch(t *testing.T, tick int, expected, got arena.State) {
+	if !expected.Equal(got) || expected.GameIsOver != got.GameIsOver {
+		t.Error("Replayed state differs at tick:", tick)
+	}
+	for i := range expected.Snakes {
+		if !expected.Snakes[i].Equal(got.Snakes[i]) || expected.Snakes[i].IsAlive != got.Snakes[i].IsAlive {
+			t.Error("Replayed snake differs at tick:", tick, "snake:", i)
+		}
+	}
+}
+
+func TestRecorderRecordsInputs(t *testing.T) {
+	rec, _ := recordGame(t)
+	if len(rec.Spawns) != 2 {
+		t.Error("Wrong number of spawns: Expected:", 2, "Got:", len(rec.Spawns))
+	}
+	if len(rec.Inputs) != 6 {
+		t.Error("Wrong number of inputs: Expected:", 6, "Got:", len(rec.Inputs))
+	}
+	if rec.Ticks != 15 {
+		t.Error("Wrong number of ticks: Expected:", 15, "Got:", rec.Ticks)
+	}
+}
+
+func TestRecorderRejectsLateSnakes(t *testing.T) {
+	r := NewRecorder(arena.Options{Width: 40, Height: 20, Seed: 7})
+	r.Tick()
+	if _, err := r.AddSnake(10, 5, 5, arena.EAST); err == nil {
+		t.Error("Adding a snake after the first tick should fail.")
+	}
+}
+
+func TestPlayerReplaysGame(t *testing.T) {
+	rec, states := recordGame(t)
+	p, err := NewPlayer(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStatesMatch(t, 0, states[0], p.State())
+	for i := 1; !p.Done(); i++ {
+		p.Tick()
+		assertStatesMatch(t, i, states[i], p.State())
+	}
+	if p.Position() != rec.Ticks {
+		t.Error("Player should stop at the last tick:", p.Position())
+	}
+}
+
+func TestPlayerSeek(t *testing.T) {
+	rec, states := recordGame(t)
+	p, err := NewPlayer(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tick := range []int{10, 3, 3, 12, 0, 15} {
+		p.Seek(tick)
+		if p.Position() != tick {
+			t.Error("Wrong position after seek: Expected:", tick, "Got:", p.Position())
+		}
+		assertStatesMatch(t, tick, states[tick], p.State())
+	}
+	p.Seek(100)
+	if !p.Done() {
+		t.Error("Seeking past the end should stop at the last tick.")
+	}
+}
+
+func TestRecordSaveAndLoad(t *testing.T) {
+	rec, states := recordGame(t)
+	var buf bytes.Buffer
+	if err := rec.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPlayer(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Seek(loaded.Ticks)
+	assertStatesMatch(t, loaded.Ticks, states[len(states)-1], p.State())
+}
+
+func TestSpeedChangesAreReplayed(t *testing.T) {
+	r := NewRecorder(arena.Options{Width: 40, Height: 20, Seed: 7})
+	if _, err := r.AddSnake(10, 5, 5, arena.EAST); err != nil {
+		t.Fatal(err)
+	}
+	r.SetSnakeSpeed(0, 150)
+	r.Tick()
+	r.Tick()
+	r.SetSnakeSpeed(0, 50)
+	r.Tick()
+	r.Tick()
+	p, err := NewPlayer(r.Record())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Seek(r.Record().Ticks)
+	expected, got := r.State().Snakes[0], p.State().Snakes[0]
+	if expected.Head() != got.Head() || got.Speed != 50 {
+		t.Error("Replayed speed changes differ: Expected:", expected.Head(), 50, "Got:", got.Head(), got.Speed)
+	}
+}
+
+func TestRecorderStopsCountingAtGameOver(t *testing.T) {
+	r := NewRecorder(arena.Options{Width: 10, Height: 5, Seed: 7})
+	r.AddSnake(7, 2, 3, arena.EAST)
+	for i := 0; i < 20; i++ {
+		r.Tick()
+	}
+	if !r.State().GameIsOver || r.Record().Ticks != 3 {
+		t.Error("Ticks after game over should not be recorded: Expected:", 3, "Got:", r.Record().Ticks)
+	}
+}
+
+func TestRecorderSkipsUnchangedHeadings(t *testing.T) {
+	r := NewRecorder(arena.Options{Width: 40, Height: 20, Seed: 7})
+	if _, err := r.AddSnake(10, 5, 5, arena.EAST); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		h := arena.EAST
+		if i >= 5 {
+			h = arena.SOUTH
+		}
+		r.SetSnakeHeading(0, h)
+		r.Tick()
+	}
+	if inputs := r.Record().Inputs; len(inputs) != 1 || inputs[0].Tick != 5 {
+		t.Error("Only the turn should be recorded, got:", inputs)
+	}
+}
@@ -0,0 +1,169 @@
+package scores
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// MaxEntries is the number of scores kept for each key.
+const MaxEntries = 10
+
+const (
+	lockRetry   = 10 * time.Millisecond
+	lockTimeout = 2 * time.Second
+	// staleLock is the age after which a lock file is assumed to be left
+	// behind by a game that crashed.
+	staleLock = 10 * time.Second
+)
+
+type Entry struct {
+	Name  string
+	Score int
+	Time  time.Time
+}
+
+// Table maps keys, e.g. the game mode, arena size and number of players, to
+// their best scores, highest first.
+type Table map[string][]Entry
+
+// rank is the index a score would get in entries, or -1 if it is too low
+// to be kept. Later scores rank below earlier ones with the same value.
+func rank(entries []Entry, score int) int {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].Score < score })
+	if i >= MaxEntries {
+		return -1
+	}
+	return i
+}
+
+// Store keeps a Table in a JSON file. Every change re-reads the file while
+// holding a lock file, so several games can share a store.
+type Store struct {
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath is in the user's data directory, $XDG_DATA_HOME or
+// ~/.local/share.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "go-retro", "snake-scores.json")
+}
+
+// Load returns an empty table if the file does not exist yet.
+func (s *Store) Load() (Table, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return Table{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := Table{}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Qualifies tells whether a score would make it into the table.
+func (s *Store) Qualifies(key string, score int) (bool, error) {
+	t, err := s.Load()
+	if err != nil {
+		return false, err
+	}
+	return score > 0 && rank(t[key], score) != -1, nil
+}
+
+// Add inserts an entry and returns its rank, or -1 if the score is too low
+// to be kept.
+func (s *Store) Add(key string, e Entry) (int, error) {
+	unlock, err := s.lock()
+	if err != nil {
+		return -1, err
+	}
+	defer unlock()
+	t, err := s.Load()
+	if err != nil {
+		return -1, err
+	}
+	i := rank(t[key], e.Score)
+	if i == -1 {
+		return -1, nil
+	}
+	entries := append(t[key][:i:i], e)
+	entries = append(entries, t[key][i:]...)
+	if len(entries) > MaxEntries {
+		entries = entries[:MaxEntries]
+	}
+	t[key] = entries
+	return i, s.save(t)
+}
+
+// save writes the table to a temporary file first, so readers never see a
+// partly written table.
+func (s *Store) save(t Table) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".snake-scores-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+func (s *Store) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return nil, err
+	}
+	path := s.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("Timed out waiting for the high-score lock.")
+		}
+		time.Sleep(lockRetry)
+	}
+}
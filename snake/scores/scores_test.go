@@ -0,0 +1,97 @@
+package scores
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func tempStore(t *testing.T) *Store {
+	return NewStore(filepath.Join(t.TempDir(), "data", "scores.json"))
+}
+
+func TestMissingFileIsEmpty(t *testing.T) {
+	table, err := tempStore(t).Load()
+	if err != nil || len(table) != 0 {
+		t.Error("Missing file should load as an empty table:", table, err)
+	}
+}
+
+func TestAddKeepsBestScores(t *testing.T) {
+	s := tempStore(t)
+	for i := 0; i < MaxEntries+5; i++ {
+		if _, err := s.Add("survival", Entry{Name: fmt.Sprint(i), Score: i % 7}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	table, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := table["survival"]
+	if len(entries) != MaxEntries {
+		t.Error("Wrong number of entries: Expected:", MaxEntries, "Got:", len(entries))
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Score > entries[i-1].Score {
+			t.Error("Entries should be sorted highest first:", entries)
+		}
+	}
+	if entries[0].Score != 6 || entries[0].Name != "6" {
+		t.Error("Earlier scores should rank above later equal ones:", entries[0])
+	}
+	if rank, _ := s.Add("survival", Entry{Name: "low", Score: 0}); rank != -1 {
+		t.Error("Low scores should not be added, got rank:", rank)
+	}
+	if ok, _ := s.Qualifies("survival", 6); !ok {
+		t.Error("A new best score should qualify.")
+	}
+	if ok, _ := s.Qualifies("timed", 0); ok {
+		t.Error("Zero scores should never qualify.")
+	}
+}
+
+func TestConcurrentAdds(t *testing.T) {
+	s := tempStore(t)
+	var wg sync.WaitGroup
+	for i := 0; i < MaxEntries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every game instance has its own store for the same file.
+			if _, err := NewStore(s.path).Add("survival", Entry{Name: fmt.Sprint(i), Score: i + 1}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	table, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table["survival"]) != MaxEntries {
+		t.Error("Concurrent scores were lost:", table["survival"])
+	}
+}
+
+func TestStaleLockIsRemoved(t *testing.T) {
+	s := tempStore(t)
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	lock := s.path + ".lock"
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLock)
+	os.Chtimes(lock, old, old)
+	if _, err := s.Add("survival", Entry{Name: "a", Score: 1}); err != nil {
+		t.Error("Stale lock should be ignored:", err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Error("Lock should be released after adding a score.")
+	}
+}
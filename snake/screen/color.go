@@ -0,0 +1,161 @@
+package screen
+
+import (
+	"errors"
+	"strings"
+)
+
+// ColorMode is the number of colors a terminal can show.
+type ColorMode int
+
+const (
+	Colors8 = ColorMode(iota)
+	Colors256
+	TrueColor
+)
+
+var colorModes = map[string]ColorMode{
+	"8":         Colors8,
+	"256":       Colors256,
+	"truecolor": TrueColor,
+}
+
+// ParseColorMode accepts 8, 256, truecolor or auto, which guesses the mode
+// from the environment variables read by getenv.
+func ParseColorMode(name string, getenv func(string) string) (ColorMode, error) {
+	if name == "auto" {
+		return detectColorMode(getenv), nil
+	}
+	if mode, ok := colorModes[name]; ok {
+		return mode, nil
+	}
+	return Colors8, errors.New("Unknown color mode: " + name)
+}
+
+func detectColorMode(getenv func(string) string) ColorMode {
+	switch {
+	case getenv("COLORTERM") == "truecolor" || getenv("COLORTERM") == "24bit":
+		return TrueColor
+	case strings.Contains(getenv("TERM"), "256color"):
+		return Colors256
+	}
+	return Colors8
+}
+
+const (
+	paletteColor Attribute = 1 << 24
+	rgbColor     Attribute = 1 << 25
+)
+
+// Palette is color n of the 256 color palette of xterm.
+func Palette(n uint8) Attribute {
+	return paletteColor | Attribute(n)
+}
+
+func RGB(r, g, b uint8) Attribute {
+	return rgbColor | Attribute(r)<<16 | Attribute(g)<<8 | Attribute(b)
+}
+
+// basicColors are the xterm values of the first 16 palette colors.
+var basicColors = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the values of each component in the 6x6x6 color cube of
+// palette colors 16 to 231.
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// The conversions below expect a color without attributes other than the
+// default one.
+
+func (c Attribute) rgb() (uint8, uint8, uint8) {
+	switch {
+	case c&rgbColor != 0:
+		return uint8(c >> 16), uint8(c >> 8), uint8(c)
+	case c&paletteColor == 0:
+		v := basicColors[c-ColorBlack]
+		return v[0], v[1], v[2]
+	}
+	n := int(uint8(c))
+	switch {
+	case n < 16:
+		v := basicColors[n]
+		return v[0], v[1], v[2]
+	case n < 232:
+		n -= 16
+		return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+	}
+	gray := uint8(8 + 10*(n-232))
+	return gray, gray, gray
+}
+
+// palette returns the index of the closest palette color.
+func (c Attribute) palette() uint8 {
+	switch {
+	case c&paletteColor != 0:
+		return uint8(c)
+	case c&rgbColor == 0:
+		return uint8(c - ColorBlack)
+	}
+	r, g, b := c.rgb()
+	cube := 16 + 36*closestLevel(r) + 6*closestLevel(g) + closestLevel(b)
+	gray := 232 + (clamp((int(r)+int(g)+int(b))/3-3, 0, 239))/10
+	if distance(Palette(uint8(gray)), c) < distance(Palette(uint8(cube)), c) {
+		return uint8(gray)
+	}
+	return uint8(cube)
+}
+
+// named returns the closest of the eight named colors.
+func (c Attribute) named() Attribute {
+	if c&(paletteColor|rgbColor) == 0 {
+		return c
+	}
+	if c&paletteColor != 0 && uint8(c) < 16 {
+		return ColorBlack + Attribute(uint8(c)%8)
+	}
+	best := ColorBlack
+	for named := ColorBlack; named <= ColorWhite; named++ {
+		if distance(named, c) < distance(best, c) {
+			best = named
+		}
+	}
+	return best
+}
+
+func closestLevel(v uint8) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(int(level)-int(v)) < abs(int(cubeLevels[best])-int(v)) {
+			best = i
+		}
+	}
+	return best
+}
+
+func distance(c1, c2 Attribute) int {
+	r1, g1, b1 := c1.rgb()
+	r2, g2, b2 := c2.rgb()
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func clamp(x, low, high int) int {
+	if x < low {
+		return low
+	}
+	if x > high {
+		return high
+	}
+	return x
+}
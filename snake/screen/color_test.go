@@ -0,0 +1,50 @@
+package screen
+
+import (
+	"testing"
+)
+
+func TestColorConversions(t *testing.T) {
+	cases := []struct {
+		color   Attribute
+		palette uint8
+		named   Attribute
+	}{
+		{ColorGreen, 2, ColorGreen},
+		{Palette(9), 9, ColorRed},
+		{Palette(21), 21, ColorBlue},
+		{RGB(0, 0, 255), 21, ColorBlue},
+		{RGB(230, 159, 0), 178, ColorYellow},
+		{RGB(200, 200, 200), 251, ColorWhite},
+		{RGB(20, 20, 20), 233, ColorBlack},
+	}
+	for _, c := range cases {
+		if got := c.color.palette(); got != c.palette {
+			t.Error("Wrong palette color for:", c.color, "Expected:", c.palette, "Got:", got)
+		}
+		if got := c.color.named(); got != c.named {
+			t.Error("Wrong named color for:", c.color, "Expected:", c.named, "Got:", got)
+		}
+	}
+	if r, g, b := Palette(232 + 23).rgb(); r != 238 || g != 238 || b != 238 {
+		t.Error("Wrong gray:", r, g, b)
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	env := map[string]string{"TERM": "xterm-256color"}
+	getenv := func(key string) string { return env[key] }
+	if mode, _ := ParseColorMode("auto", getenv); mode != Colors256 {
+		t.Error("Expected 256 colors from TERM. Got:", mode)
+	}
+	env["COLORTERM"] = "truecolor"
+	if mode, _ := ParseColorMode("auto", getenv); mode != TrueColor {
+		t.Error("Expected true color from COLORTERM. Got:", mode)
+	}
+	if mode, _ := ParseColorMode("8", getenv); mode != Colors8 {
+		t.Error("Expected 8 colors. Got:", mode)
+	}
+	if _, err := ParseColorMode("16", getenv); err == nil {
+		t.Error("Unknown color modes should be rejected.")
+	}
+}
@@ -0,0 +1,94 @@
+package screen
+
+import (
+	"strings"
+)
+
+type Cell struct {
+	Ch     rune
+	Fg, Bg Attribute
+}
+
+// Memory is a Screen that keeps its cells in memory, so that drawing can be
+// inspected by tests. Cells outside of it are dropped.
+type Memory struct {
+	width, height int
+	cells         []Cell
+	events        chan Event
+}
+
+// eventBuffer is the number of events Send can queue without a reader.
+const eventBuffer = 16
+
+func NewMemory(width, height int) *Memory {
+	m := &Memory{events: make(chan Event, eventBuffer)}
+	m.resize(width, height)
+	return m
+}
+
+func (m *Memory) resize(width, height int) {
+	m.width, m.height = width, height
+	m.cells = make([]Cell, width*height)
+	m.Clear()
+}
+
+func (m *Memory) SetCell(x, y int, ch rune, fg, bg Attribute) {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height {
+		return
+	}
+	m.cells[y*m.width+x] = Cell{ch, fg, bg}
+}
+
+func (m *Memory) Size() (int, int) {
+	return m.width, m.height
+}
+
+func (m *Memory) Clear() {
+	for i := range m.cells {
+		m.cells[i] = Cell{Ch: ' '}
+	}
+}
+
+func (m *Memory) Flush() error {
+	return nil
+}
+
+func (m *Memory) Events() <-chan Event {
+	return m.events
+}
+
+func (m *Memory) Close() {
+}
+
+// Send queues an event for Events.
+func (m *Memory) Send(ev Event) {
+	m.events <- ev
+}
+
+// Resize clears the screen and sends the resize event a terminal would.
+func (m *Memory) Resize(width, height int) {
+	m.resize(width, height)
+	m.Send(Event{Type: EventResize, Width: width, Height: height})
+}
+
+func (m *Memory) Cell(x, y int) Cell {
+	if x < 0 || x >= m.width || y < 0 || y >= m.height {
+		return Cell{}
+	}
+	return m.cells[y*m.width+x]
+}
+
+// String returns the characters of the screen, a line per row with
+// trailing spaces removed.
+func (m *Memory) String() string {
+	var b strings.Builder
+	for y := 0; y < m.height; y++ {
+		row := make([]rune, m.width)
+		for x := range row {
+			row[x] = m.cells[y*m.width+x].Ch
+		}
+		b.WriteString(strings.TrimRight(string(row), " "))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
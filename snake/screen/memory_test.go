@@ -0,0 +1,38 @@
+package screen
+
+import (
+	"testing"
+)
+
+func TestMemoryKeepsCells(t *testing.T) {
+	m := NewMemory(4, 2)
+	m.SetCell(1, 0, 'a', ColorRed|AttrBold, ColorDefault)
+	m.SetCell(3, 1, 'b', ColorGreen, ColorBlack)
+	m.SetCell(4, 0, 'x', 0, 0)
+	m.SetCell(-1, 1, 'x', 0, 0)
+	if got := m.String(); got != " a\n   b\n" {
+		t.Errorf("Wrong screen content: %q", got)
+	}
+	if c := m.Cell(1, 0); c != (Cell{'a', ColorRed | AttrBold, ColorDefault}) {
+		t.Error("Wrong cell:", c)
+	}
+	m.Clear()
+	if got := m.String(); got != "\n\n" {
+		t.Errorf("Screen should be empty after Clear: %q", got)
+	}
+}
+
+func TestMemoryEvents(t *testing.T) {
+	m := NewMemory(4, 2)
+	m.Send(Event{Type: EventKey, Ch: 'q'})
+	m.Resize(8, 3)
+	if ev := <-m.Events(); ev.Type != EventKey || ev.Ch != 'q' {
+		t.Error("Wrong key event:", ev)
+	}
+	if ev := <-m.Events(); ev.Type != EventResize || ev.Width != 8 || ev.Height != 3 {
+		t.Error("Wrong resize event:", ev)
+	}
+	if w, h := m.Size(); w != 8 || h != 3 {
+		t.Error("Wrong size after resize:", w, h)
+	}
+}
@@ -0,0 +1,88 @@
+// Package screen is the character cell display the games draw on, with a
+// terminal implementation and an in-memory one for tests.
+package screen
+
+// Screen is a grid of character cells. Drawing goes to a back buffer that
+// Flush shows.
+type Screen interface {
+	SetCell(x, y int, ch rune, fg, bg Attribute)
+	Size() (width, height int)
+	Clear()
+	Flush() error
+	// Events delivers key presses and size changes.
+	Events() <-chan Event
+	Close()
+}
+
+// Attribute is a color, one of the named ones, a Palette or an RGB color,
+// optionally combined with AttrBold, AttrUnderline and AttrReverse.
+type Attribute uint64
+
+const (
+	ColorDefault Attribute = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+const (
+	AttrBold Attribute = 1 << (iota + 32)
+	AttrUnderline
+	AttrReverse
+
+	colorMask = 1<<32 - 1
+)
+
+type EventType int
+
+const (
+	EventKey = EventType(iota)
+	EventResize
+)
+
+type Key int
+
+const (
+	KeyUnknown = Key(iota)
+	KeyArrowUp
+	KeyArrowDown
+	KeyArrowLeft
+	KeyArrowRight
+	KeyEnter
+	KeyEsc
+	KeySpace
+	KeyTab
+	KeyBackspace
+	KeyInsert
+	KeyDelete
+	KeyHome
+	KeyEnd
+	KeyPgup
+	KeyPgdn
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+)
+
+// Event is a key press, either a character in Ch or a special Key, or a
+// change of the screen size.
+type Event struct {
+	Type          EventType
+	Key           Key
+	Ch            rune
+	Width, Height int
+}
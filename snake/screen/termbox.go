@@ -0,0 +1,122 @@
+package screen
+
+import (
+	"github.com/nsf/termbox-go"
+)
+
+var termboxKeys = map[termbox.Key]Key{
+	termbox.KeyArrowUp:    KeyArrowUp,
+	termbox.KeyArrowDown:  KeyArrowDown,
+	termbox.KeyArrowLeft:  KeyArrowLeft,
+	termbox.KeyArrowRight: KeyArrowRight,
+	termbox.KeyEnter:      KeyEnter,
+	termbox.KeyEsc:        KeyEsc,
+	termbox.KeySpace:      KeySpace,
+	termbox.KeyTab:        KeyTab,
+	termbox.KeyBackspace:  KeyBackspace,
+	termbox.KeyBackspace2: KeyBackspace,
+	termbox.KeyInsert:     KeyInsert,
+	termbox.KeyDelete:     KeyDelete,
+	termbox.KeyHome:       KeyHome,
+	termbox.KeyEnd:        KeyEnd,
+	termbox.KeyPgup:       KeyPgup,
+	termbox.KeyPgdn:       KeyPgdn,
+	termbox.KeyF1:         KeyF1,
+	termbox.KeyF2:         KeyF2,
+	termbox.KeyF3:         KeyF3,
+	termbox.KeyF4:         KeyF4,
+	termbox.KeyF5:         KeyF5,
+	termbox.KeyF6:         KeyF6,
+	termbox.KeyF7:         KeyF7,
+	termbox.KeyF8:         KeyF8,
+	termbox.KeyF9:         KeyF9,
+	termbox.KeyF10:        KeyF10,
+	termbox.KeyF11:        KeyF11,
+	termbox.KeyF12:        KeyF12,
+}
+
+var termboxModes = map[ColorMode]termbox.OutputMode{
+	Colors8:   termbox.OutputNormal,
+	Colors256: termbox.Output256,
+	TrueColor: termbox.OutputRGB,
+}
+
+type termboxScreen struct {
+	events chan Event
+	mode   ColorMode
+}
+
+// NewTermbox takes over the terminal until Close is called. Colors are
+// converted to the closest ones the color mode has.
+func NewTermbox(mode ColorMode) (Screen, error) {
+	if err := termbox.Init(); err != nil {
+		return nil, err
+	}
+	termbox.SetOutputMode(termboxModes[mode])
+	s := &termboxScreen{events: make(chan Event), mode: mode}
+	go s.poll()
+	return s, nil
+}
+
+func (s *termboxScreen) poll() {
+	for {
+		ev := termbox.PollEvent()
+		switch ev.Type {
+		case termbox.EventKey:
+			s.events <- Event{Type: EventKey, Key: termboxKeys[ev.Key], Ch: ev.Ch}
+		case termbox.EventResize:
+			s.events <- Event{Type: EventResize, Width: ev.Width, Height: ev.Height}
+		}
+	}
+}
+
+// termboxAttribute relies on the named colors being in the same order as
+// the termbox ones.
+func (s *termboxScreen) termboxAttribute(a Attribute) termbox.Attribute {
+	var t termbox.Attribute
+	switch c := a & colorMask; {
+	case c == ColorDefault:
+		// With TrueColor termbox shows the default color as black when
+		// attributes are set on it, so themes avoid that combination.
+	case s.mode == Colors256:
+		t = termbox.Attribute(c.palette()) + 1
+	case s.mode == TrueColor:
+		t = termbox.RGBToAttribute(c.rgb())
+	default:
+		t = termbox.Attribute(c.named())
+	}
+	if a&AttrBold != 0 {
+		t |= termbox.AttrBold
+	}
+	if a&AttrUnderline != 0 {
+		t |= termbox.AttrUnderline
+	}
+	if a&AttrReverse != 0 {
+		t |= termbox.AttrReverse
+	}
+	return t
+}
+
+func (s *termboxScreen) SetCell(x, y int, ch rune, fg, bg Attribute) {
+	termbox.SetCell(x, y, ch, s.termboxAttribute(fg), s.termboxAttribute(bg))
+}
+
+func (s *termboxScreen) Size() (int, int) {
+	return termbox.Size()
+}
+
+func (s *termboxScreen) Clear() {
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+}
+
+func (s *termboxScreen) Flush() error {
+	return termbox.Flush()
+}
+
+func (s *termboxScreen) Events() <-chan Event {
+	return s.events
+}
+
+func (s *termboxScreen) Close() {
+	termbox.Close()
+}
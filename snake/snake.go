@@ -2,16 +2,198 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"github.com/dragonfi/go-retro/snake/arena"
+	"github.com/dragonfi/go-retro/snake/replay"
+	"github.com/dragonfi/go-retro/snake/scores"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"os"
 )
 
 func main() {
-	var player_number int
-	flag.IntVar(&player_number, "p", 1, "The number of players. (1-4)")
+	var config Config
+	var record, replay_file, serve_addr, connect_addr, map_file, speed, colors, controls_file, scores_file string
+	var maps_dir string
+	var print_scores bool
+	var width, height int
+	flag.IntVar(&config.Players, "p", 1, "The number of players. (1-4)")
+	flag.IntVar(&config.Bots, "bots", 0, "The number of computer controlled snakes. (0-3)")
+	flag.StringVar(&config.BotKind, "bot-kind", "flood", "Bot controller. (random-safe, greedy, bfs, flood)")
+	flag.IntVar(&config.BotSpeed, "bot-speed", arena.NormalSpeed, "Speed of the bots in percent of the players' speed.")
+	flag.Int64Var(&config.Seed, "seed", 0, "Random seed for point items. (0: new seed every game)")
+	flag.StringVar(&config.Items, "items", "classic", "Item set. (classic, mixed)")
+	flag.IntVar(&config.MaxItems, "max-items", 1, "The number of items in the arena.")
+	flag.BoolVar(&config.Wrap, "wrap", false, "Snakes leaving the arena reappear on the opposite side.")
+	flag.StringVar(&config.Goal, "rules", "survival", "How a game is won. (survival, last-standing, length, timed)")
+	flag.IntVar(&config.Length, "length", 20, "The length to reach with -rules length.")
+	flag.IntVar(&config.TimeLimit, "time", 600, "The number of ticks of a game with -rules timed.")
+	flag.IntVar(&config.Rounds, "rounds", 1, "Play a best-of match of this many games.")
+	flag.StringVar(&speed, "speed", "normal", "Ticks per second, or one of: slow, normal, fast, insane.")
+	flag.BoolVar(&config.Progressive, "progressive", false, "Speed up as the snakes grow.")
+	flag.BoolVar(&config.Wide, "wide", false, "Draw every arena cell two columns wide, so the arena looks square.")
+	flag.StringVar(&config.Glyphs, "glyphs", "ascii", "Characters the arena is drawn with. (ascii, box, block)")
+	flag.StringVar(&config.Theme, "theme", "classic", "Colors of the snakes and items. (classic, colorblind, light, mono)")
+	flag.StringVar(&colors, "colors", "auto", "Colors the terminal can show. (auto, 8, 256, truecolor)")
+	flag.StringVar(&record, "record", "", "Save a replay of the last game to this file.")
+	flag.StringVar(&replay_file, "replay", "", "Play back a replay file instead of starting a game.")
+	flag.StringVar(&controls_file, "controls", DefaultControlsPath(), "Load key bindings from this JSON file.")
+	flag.StringVar(&scores_file, "scores-file", scores.DefaultPath(), "Keep the high scores in this file. (empty: no high scores)")
+	flag.BoolVar(&print_scores, "scores", false, "Print the high scores and exit.")
+	flag.StringVar(&map_file, "map", "", "Load the arena from a map file. (see maps/)")
+	flag.StringVar(&maps_dir, "maps", "", "Directory of the maps offered in the menu instead of the bundled ones.")
+	flag.StringVar(&serve_addr, "serve", "", "Host a network game for -p players on this address, e.g. :7777.")
+	flag.StringVar(&connect_addr, "connect", "", "Join a network game hosted on this address.")
+	flag.IntVar(&width, "width", 60, "Arena width when hosting a network game.")
+	flag.IntVar(&height, "height", 20, "Arena height when hosting a network game.")
 	flag.Parse()
-	x, y := Init()
-	defer Close()
-	offsetx, offsety := 2, 2
-	aw := NewArenaWidget(offsetx, offsety, x-2*offsetx, y-2*offsety, player_number)
 
+	if scores_file != "" {
+		config.Scores = scores.NewStore(scores_file)
+	}
+	if print_scores {
+		if config.Scores == nil {
+			fmt.Fprintln(os.Stderr, "No high-score file given.")
+			os.Exit(1)
+		}
+		table, err := config.Scores.Load()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Cannot load high scores:", err)
+			os.Exit(1)
+		}
+		printScores(os.Stdout, table)
+		return
+	}
+
+	var err error
+	if config.Speed, err = parseSpeed(speed); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if config.Colors, err = screen.ParseColorMode(colors, os.Getenv); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if controls_file != "" {
+		controls, err := LoadControls(controls_file)
+		if err == nil {
+			config.Controls = controls
+		} else if !os.IsNotExist(err) || controls_file != DefaultControlsPath() {
+			fmt.Fprintln(os.Stderr, "Cannot load controls:", err)
+			os.Exit(1)
+		}
+	}
+
+	if map_file != "" {
+		level, err := arena.LoadLevel(map_file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Cannot load map:", err)
+			os.Exit(1)
+		}
+		config.Level = &level
+	}
+
+	if err := config.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if serve_addr != "" {
+		if err := serve(serve_addr, config, width, height); err != nil {
+			fmt.Fprintln(os.Stderr, "Server error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if connect_addr != "" {
+		if err := connect(connect_addr, config); err != nil {
+			fmt.Fprintln(os.Stderr, "Connection error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if replay_file != "" {
+		rec, err := replay.LoadFile(replay_file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Cannot load replay:", err)
+			os.Exit(1)
+		}
+		runReplay(rec, config)
+		return
+	}
+
+	interactive := true
+	flag.Visit(func(f *flag.Flag) {
+		for _, name := range setupFlags {
+			if f.Name == name {
+				interactive = false
+			}
+		}
+	})
+	maps := BundledMaps()
+	if maps_dir != "" {
+		maps = os.DirFS(maps_dir)
+	}
+	levels, err := LoadLevels(maps)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot load map:", err)
+		os.Exit(1)
+	}
+
+	s, err := screen.NewTermbox(config.Colors)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot open the terminal:", err)
+		os.Exit(1)
+	}
+	var aw *ArenaWidget
+	menu := NewMenuWidget(s, 2, 2, config, levels)
+	for {
+		if interactive {
+			if !menu.Run() {
+				break
+			}
+			config = menu.Config()
+		}
+		game, err := NewArenaWidget(s, config)
+		if err != nil && interactive {
+			menu.message = err.Error()
+			continue
+		}
+		if err != nil {
+			s.Close()
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		aw = game
+		aw.Run()
+		if !interactive {
+			break
+		}
+	}
+	s.Close()
+
+	if record != "" && aw != nil {
+		if err := replay.SaveFile(record, aw.Record()); err != nil {
+			fmt.Fprintln(os.Stderr, "Cannot save replay:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func runReplay(rec replay.Record, config Config) {
+	s, err := screen.NewTermbox(config.Colors)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Cannot open the terminal:", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+	aw, err := NewReplayWidget(s, rec, config)
+	if err != nil {
+		s.Close()
+		fmt.Fprintln(os.Stderr, "Cannot play replay:", err)
+		os.Exit(1)
+	}
 	aw.Run()
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"github.com/dragonfi/go-retro/snake/screen"
+)
+
+type colorTheme struct {
+	snakes [4]screen.Attribute
+	// items is keyed by the name of the item kind.
+	items map[string]screen.Attribute
+	// distinctBodies draws every snake with a body of its own, for themes
+	// whose colors do not tell the snakes apart.
+	distinctBodies bool
+}
+
+var colorThemes = map[string]colorTheme{
+	"classic": {
+		snakes: [4]screen.Attribute{
+			screen.ColorGreen | screen.AttrBold,
+			screen.ColorYellow | screen.AttrBold,
+			screen.ColorRed | screen.AttrBold,
+			screen.ColorBlue | screen.AttrBold,
+		},
+		items: map[string]screen.Attribute{
+			"grow":       screen.ColorCyan | screen.AttrBold,
+			"shrink":     screen.ColorRed,
+			"speed-up":   screen.ColorYellow,
+			"slow-down":  screen.ColorBlue,
+			"invincible": screen.ColorWhite | screen.AttrBold,
+			"reverse":    screen.ColorMagenta,
+			"bonus":      screen.ColorYellow | screen.AttrBold,
+		},
+	},
+	// colorblind uses the Okabe-Ito palette, which stays distinguishable
+	// with the common kinds of color blindness.
+	"colorblind": {
+		snakes: [4]screen.Attribute{
+			screen.RGB(230, 159, 0) | screen.AttrBold,
+			screen.RGB(86, 180, 233) | screen.AttrBold,
+			screen.RGB(0, 158, 115) | screen.AttrBold,
+			screen.RGB(204, 121, 167) | screen.AttrBold,
+		},
+		items: map[string]screen.Attribute{
+			"grow":       screen.RGB(240, 228, 66) | screen.AttrBold,
+			"shrink":     screen.RGB(213, 94, 0),
+			"speed-up":   screen.RGB(230, 159, 0),
+			"slow-down":  screen.RGB(0, 114, 178),
+			"invincible": screen.ColorWhite | screen.AttrBold,
+			"reverse":    screen.RGB(204, 121, 167),
+			"bonus":      screen.RGB(240, 228, 66) | screen.AttrBold,
+		},
+	},
+	// light has dark colors that can be read on a light background.
+	"light": {
+		snakes: [4]screen.Attribute{
+			screen.RGB(0, 110, 0) | screen.AttrBold,
+			screen.RGB(0, 0, 170) | screen.AttrBold,
+			screen.RGB(170, 0, 0) | screen.AttrBold,
+			screen.RGB(120, 0, 150) | screen.AttrBold,
+		},
+		items: map[string]screen.Attribute{
+			"grow":       screen.RGB(0, 120, 120) | screen.AttrBold,
+			"shrink":     screen.RGB(170, 0, 0),
+			"speed-up":   screen.RGB(150, 90, 0),
+			"slow-down":  screen.RGB(0, 0, 170),
+			"invincible": screen.ColorBlack | screen.AttrBold,
+			"reverse":    screen.RGB(120, 0, 150),
+			"bonus":      screen.RGB(150, 90, 0) | screen.AttrBold,
+		},
+	},
+	"mono": {
+		items:          map[string]screen.Attribute{},
+		distinctBodies: true,
+	},
+}
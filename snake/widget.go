@@ -1,33 +1,31 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"github.com/dragonfi/go-retro/snake/arena"
-	"github.com/nsf/termbox-go"
+	"github.com/dragonfi/go-retro/snake/netplay"
+	"github.com/dragonfi/go-retro/snake/replay"
+	"github.com/dragonfi/go-retro/snake/screen"
 	"time"
+	"unicode/utf8"
 )
 
-var colors = map[string]termbox.Attribute{
-	"snake1":    termbox.ColorGreen | termbox.AttrBold,
-	"snake2":    termbox.ColorYellow | termbox.AttrBold,
-	"snake3":    termbox.ColorRed | termbox.AttrBold,
-	"snake4":    termbox.ColorBlue | termbox.AttrBold,
-	"pointItem": termbox.ColorCyan | termbox.AttrBold,
-}
-
-func getSnakeColor(i int) termbox.Attribute {
-	key := ""
-	switch i {
-	case 0:
-		key = "snake1"
-	case 1:
-		key = "snake2"
-	case 2:
-		key = "snake3"
-	case 3:
-		key = "snake4"
-	}
-	return colors[key]
+// growthPerSpeedUp is how many cells the longest snake has to grow for a
+// progressive game to speed up by a tick per second.
+const growthPerSpeedUp = 3
+
+// footerHeight is the number of rows kept below the arena for help text.
+const footerHeight = 1
+
+var itemGlyphs = map[arena.ItemKind]rune{
+	arena.GROW:       '*',
+	arena.SHRINK:     '-',
+	arena.SPEED_UP:   '>',
+	arena.SLOW_DOWN:  '<',
+	arena.INVINCIBLE: '!',
+	arena.REVERSE:    '?',
+	arena.BONUS:      '$',
 }
 
 type Position struct {
@@ -35,39 +33,113 @@ type Position struct {
 }
 
 type ArenaWidget struct {
-	arena   arena.Arena
-	offset  Position
-	size    Position
-	state   arena.State
-	running bool
-	players int
-	KeyMap  KeyMap
-	RuneMap RuneMap
+	arena        arena.Arena
+	recorder     *replay.Recorder
+	player       *replay.Player
+	remote       *netplay.Client
+	screen       screen.Screen
+	offset       Position
+	size         Position
+	screenSize   Position
+	tooSmall     bool
+	state        arena.State
+	running      bool
+	paused       bool
+	fastForward  bool
+	config       Config
+	bots         []arena.Controller
+	lastDeath    string
+	showControls bool
+	entry        *nameEntry
+	scoreMessage string
+	match        *arena.Match
+	baseLength   int
+	KeyMap       KeyMap
+	RuneMap      RuneMap
 }
 
 func (w *ArenaWidget) Tick() {
-	w.arena.Tick()
+	for i, bot := range w.bots {
+		snake := w.config.Players + i
+		if w.state.WillMove(snake) {
+			w.arena.SetSnakeHeading(snake, bot.Heading(w.state, snake))
+		}
+	}
+	events := w.arena.Tick()
 	w.state = w.arena.State()
+	for _, e := range events {
+		if e.Kind == arena.SNAKE_DIED {
+			w.lastDeath = w.deathMessage(e)
+		}
+		if e.Kind == arena.GAME_OVER {
+			if w.match != nil {
+				w.match.Record(e.Winner)
+			}
+			w.startNameEntry()
+		}
+	}
+}
+
+func (w ArenaWidget) deathMessage(e arena.Event) string {
+	switch {
+	case e.Cause == arena.HIT_WALL:
+		return fmt.Sprintf("%s hit a wall", w.snakeName(e.Snake))
+	case e.Cause == arena.HIT_SELF:
+		return fmt.Sprintf("%s bit itself", w.snakeName(e.Snake))
+	case e.Killer != -1:
+		return fmt.Sprintf("%s was killed by %s", w.snakeName(e.Snake), w.snakeName(e.Killer))
+	}
+	return fmt.Sprintf("%s crashed head-on", w.snakeName(e.Snake))
 }
 
 func (w *ArenaWidget) SetSnakeHeading(snake int, direction arena.Direction) {
 	w.arena.SetSnakeHeading(snake, direction)
 }
 
-func (w ArenaWidget) setCell(x, y int, r rune, fg, bg termbox.Attribute) {
-	termbox.SetCell(w.offset.X+x, w.offset.Y+y, r, fg, bg)
+func (w ArenaWidget) setCell(x, y int, r rune, fg, bg screen.Attribute) {
+	w.screen.SetCell(w.offset.X+x, w.offset.Y+y, r, fg, bg)
 }
 
 func (w ArenaWidget) putString(x, y int, str string) {
-	putString(w.offset.X+x, w.offset.Y+y, str)
+	putString(w.screen, w.offset.X+x, w.offset.Y+y, str)
+}
+
+// columns is the number of terminal columns the arena takes.
+func (w ArenaWidget) columns() int {
+	if w.config.Wide {
+		return 2 * w.state.Size.X
+	}
+	return w.state.Size.X
+}
+
+func (w ArenaWidget) glyphs() glyphTheme {
+	return glyphThemes[w.config.Glyphs]
+}
+
+func (w ArenaWidget) theme() colorTheme {
+	return colorThemes[w.config.Theme]
+}
+
+// drawCell draws arena cell x, y, which is two columns wide in wide mode.
+func (w ArenaWidget) drawCell(x, y int, g cellGlyph, fg screen.Attribute) {
+	if !w.config.Wide {
+		w.setCell(x, y, g[0], fg, 0)
+		return
+	}
+	w.setCell(2*x, y, g[0], fg, 0)
+	w.setCell(2*x+1, y, g[1], fg, 0)
 }
 
 func (w ArenaWidget) drawBorder() {
-	s := w.state
-	for i := -1; i <= s.Size.X; i++ {
-		for j := -1; j <= s.Size.Y; j++ {
-			if i == -1 || i == s.Size.X || j == -1 || j == s.Size.Y {
-				w.setCell(i, j, '#', 0, 0)
+	border := w.glyphs().border
+	if w.state.Wrap {
+		border = w.glyphs().wrapBorder
+	}
+	width, height := w.columns(), w.state.Size.Y
+	for i := -1; i <= width; i++ {
+		for j := -1; j <= height; j++ {
+			if i == -1 || i == width || j == -1 || j == height {
+				w.setCell(i, j, border.char(i, j, width, height), 0, 0)
 			}
 		}
 	}
@@ -75,94 +147,374 @@ func (w ArenaWidget) drawBorder() {
 
 func (w ArenaWidget) drawSnakes() {
 	for i, snake := range w.state.Snakes {
-		w.drawSnake(getSnakeColor(i), snake)
+		w.drawSnake(i, snake)
 	}
 }
-func (w ArenaWidget) drawSnake(color termbox.Attribute, snake arena.Snake) {
+
+func (w ArenaWidget) drawSnake(id int, snake arena.Snake) {
+	body := w.glyphs().body
+	if w.theme().distinctBodies {
+		body = w.glyphs().bodies[id]
+	}
 	for i, p := range snake.Segments {
-		char := '#'
+		g := body
 		if i == 0 {
-			char = 'O'
+			g = w.glyphs().head(snake.Heading)
 		}
 		if !snake.IsAlive {
-			char = 'X'
+			g = w.glyphs().dead
 		}
-		w.setCell(p.X, p.Y, char, color, 0)
+		w.drawCell(p.X, p.Y, g, w.theme().snakes[id])
 	}
 
 }
 
-func (w ArenaWidget) drawPointItem() {
-	p := w.state.PointItem
-	w.setCell(p.X, p.Y, '*', colors["pointItem"], 0)
+func (w ArenaWidget) drawWalls() {
+	for _, p := range w.state.Walls {
+		w.drawCell(p.X, p.Y, w.glyphs().wall, 0)
+	}
+}
+
+func (w ArenaWidget) drawItems() {
+	for _, item := range w.state.Items {
+		w.drawCell(item.X, item.Y, cellGlyph{itemGlyphs[item.Kind], ' '}, w.theme().items[item.Kind.String()])
+	}
 }
 
 func (w ArenaWidget) putGameOverText() {
 	s := w.state
-	w.putString(s.Size.X/2-9, s.Size.Y/2-3, "##################")
-	w.putString(s.Size.X/2-9, s.Size.Y/2-2, "#    Game Over   #")
-	w.putString(s.Size.X/2-9, s.Size.Y/2-1, "#                #")
-	w.putString(s.Size.X/2-9, s.Size.Y/2+0, "# Enter: Restart #")
-	w.putString(s.Size.X/2-9, s.Size.Y/2+1, "# ESC: Exit      #")
-	w.putString(s.Size.X/2-9, s.Size.Y/2+2, "##################")
+	lines := []string{"Game Over", ""}
+	lines = append(lines, w.resultTable()...)
+	lines = append(lines, "")
+	if len(s.Snakes) > 1 {
+		lines = append(lines, w.resultText())
+	}
+	lines = append(lines, fmt.Sprintf("Seed: %d", s.Seed), "")
+	if entry := w.nameEntryText(); entry != "" {
+		lines = append(lines, entry, "")
+	}
+	lines = append(lines, "Enter: Restart  ESC: Exit")
+	w.putBox(lines)
+}
+
+// resultTable lists the results of every snake under a header line.
+func (w ArenaWidget) resultTable() []string {
+	nameWidth := 0
+	for i := range w.state.Snakes {
+		if n := len(w.snakeName(i)); n > nameWidth {
+			nameWidth = n
+		}
+	}
+	wins := w.match != nil && w.match.Games > 1
+	header := fmt.Sprintf("%-*s %6s %5s %5s %5s", nameWidth, "", "Points", "Kills", "Items", "Ticks")
+	if wins {
+		header += "  Wins"
+	}
+	lines := []string{header}
+	for i, snake := range w.state.Snakes {
+		line := fmt.Sprintf("%-*s %6d %5d %5d %5d", nameWidth, w.snakeName(i),
+			snake.Score, snake.Kills, snake.ItemsEaten, snake.TicksSurvived)
+		if wins {
+			line += fmt.Sprintf(" %5d", w.match.Wins[i])
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (w ArenaWidget) resultText() string {
+	winner := w.state.Winner()
+	result := "Draw."
+	if winner != -1 {
+		result = fmt.Sprintf("%s wins.", w.snakeName(winner))
+	}
+	if w.match == nil || w.match.Games == 1 {
+		return result
+	}
+	if w.match.IsOver() && w.match.Winner() == -1 {
+		return fmt.Sprintf("%s The match is drawn.", result)
+	}
+	if w.match.IsOver() {
+		return fmt.Sprintf("%s wins the match!", w.snakeName(w.match.Winner()))
+	}
+	return fmt.Sprintf("%s Game %d of best of %d.", result, w.match.Played(), w.match.Games)
+}
+
+func (w ArenaWidget) putControls() {
+	c := w.controls()
+	lines := []string{"Controls", ""}
+	players := w.config.Players
+	if w.remote != nil {
+		players = 1
+	}
+	for i := 0; i < players; i++ {
+		p := fmt.Sprintf("p%d.", i+1)
+		lines = append(lines, fmt.Sprintf("Player %d: up %s, down %s, left %s, right %s", i+1,
+			c.describe(p+"up"), c.describe(p+"down"), c.describe(p+"left"), c.describe(p+"right")))
+	}
+	lines = append(lines, "")
+	for _, action := range []string{"pause", "step", "restart", "quit", "controls"} {
+		lines = append(lines, fmt.Sprintf("%-9s %s", action+":", c.describe(action)))
+	}
+	w.putBox(lines)
+}
+
+// putBox draws lines in a frame centered on the arena. Lines too wide for
+// the arena are cut, and a frame too tall for it starts at the top border.
+func (w ArenaWidget) putBox(lines []string) {
+	width := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > width {
+			width = n
+		}
+	}
+	if max := w.columns() - 2; width > max && max > 0 {
+		width = max
+	}
+	x, y := w.columns()/2-width/2-2, w.state.Size.Y/2-len(lines)/2-1
+	if y < -1 {
+		y = -1
+	}
+	for j := 0; j < len(lines)+2; j++ {
+		for i := 0; i < width+4; i++ {
+			ch := ' '
+			if i == 0 || i == width+3 || j == 0 || j == len(lines)+1 {
+				ch = '#'
+			}
+			w.setCell(x+i, y+j, ch, 0, 0)
+		}
+	}
+	for j, line := range lines {
+		if runes := []rune(line); len(runes) > width {
+			line = string(runes[:width])
+		}
+		w.putString(x+2, y+1+j, line)
+	}
+}
+
+func (w ArenaWidget) putPausedText() {
+	s := w.state
+	w.putString(w.columns()/2-9, s.Size.Y/2-3, "##################")
+	w.putString(w.columns()/2-9, s.Size.Y/2-2, "#     Paused     #")
+	w.putString(w.columns()/2-9, s.Size.Y/2-1, "#                #")
+	w.putString(w.columns()/2-9, s.Size.Y/2+0, "# P: Resume      #")
+	w.putString(w.columns()/2-9, s.Size.Y/2+1, "# N: Single step #")
+	w.putString(w.columns()/2-9, s.Size.Y/2+2, "##################")
+}
+
+// hudHeight is the number of rows above the arena: a status line, a score
+// line for each snake and the last death.
+func hudHeight(snakes int) int {
+	return snakes + 2
+}
+
+// hudTop is the first row of the HUD relative to the arena.
+func (w ArenaWidget) hudTop() int {
+	return -1 - hudHeight(len(w.state.Snakes))
+}
+
+// minScreenSize is the terminal size needed to show the arena with its
+// border, HUD and footer.
+func (w ArenaWidget) minScreenSize() (int, int) {
+	return w.columns() + 2, hudHeight(len(w.state.Snakes)) + w.state.Size.Y + 2 + footerHeight
+}
+
+// layout centers the arena and its HUD on the screen.
+func (w *ArenaWidget) layout() {
+	sw, sh := w.screen.Size()
+	width, height := w.minScreenSize()
+	w.tooSmall = sw < width || sh < height
+	w.offset = Position{X: (sw-width)/2 + 1, Y: (sh-height)/2 + hudHeight(len(w.state.Snakes)) + 1}
+}
+
+// resize lays the widget out for the new terminal size. Local games pause
+// when the size changes, so the players can find their snakes again.
+func (w *ArenaWidget) resize() {
+	sw, sh := w.screen.Size()
+	changed := w.screenSize != (Position{}) && w.screenSize != (Position{X: sw, Y: sh})
+	w.screenSize = Position{X: sw, Y: sh}
+	w.layout()
+	if (w.tooSmall || changed) && w.remote == nil {
+		w.paused = true
+	}
+}
+
+func (w ArenaWidget) putTooSmallText() {
+	width, height := w.minScreenSize()
+	putString(w.screen, 0, 0, "The terminal is too small.")
+	putString(w.screen, 0, 1, fmt.Sprintf("Resize it to at least %dx%d.", width, height))
+}
+
+func (w ArenaWidget) putSpeed() {
+	status := fmt.Sprintf("Speed: %d", w.speed())
+	if w.config.Progressive {
+		status += " [progressive]"
+	}
+	w.putString(0, w.hudTop(), status)
 }
 
 func (w ArenaWidget) putScore() {
 	s := w.state
 	for i, snake := range s.Snakes {
-		w.putString(1, 1+i, fmt.Sprintf("Player %d: %d", i+1, len(snake.Segments)))
+		score := fmt.Sprintf("%s: %d", w.snakeName(i), snake.Score)
+		for _, kind := range []arena.ItemKind{arena.SPEED_UP, arena.SLOW_DOWN, arena.INVINCIBLE, arena.REVERSE} {
+			if snake.HasEffect(kind, s.Ticks) {
+				score += " [" + kind.String() + "]"
+			}
+		}
+		w.putString(0, w.hudTop()+1+i, score)
+	}
+	if w.lastDeath != "" {
+		w.putString(0, w.hudTop()+1+len(s.Snakes), w.lastDeath)
 	}
 }
 
+func (w ArenaWidget) snakeName(i int) string {
+	if w.remote != nil && i == w.remote.Snake() {
+		return fmt.Sprintf("Player %d (you)", i+1)
+	}
+	if i >= w.config.Players {
+		return fmt.Sprintf("Bot %d (%s)", i+1, w.config.BotKind)
+	}
+	return fmt.Sprintf("Player %d", i+1)
+}
+
 func (w ArenaWidget) Draw() {
+	if w.tooSmall {
+		w.putTooSmallText()
+		return
+	}
 	w.drawBorder()
+	w.drawWalls()
 	w.putScore()
 	w.drawSnakes()
-	w.drawPointItem()
-	if w.state.GameIsOver {
+	w.drawItems()
+	if w.showControls {
+		w.putControls()
+	} else if w.state.GameIsOver {
 		w.putGameOverText()
+	} else if w.paused && w.player == nil {
+		w.putPausedText()
+	}
+	if w.player != nil {
+		w.putReplayStatus()
+	} else if w.remote == nil {
+		w.putSpeed()
 	}
 }
 
 func (w *ArenaWidget) ResetArena() {
-	w.arena = arena.New(w.size.X, w.size.Y)
-	one := arena.Position{w.size.X / 3, w.size.Y / 3}
+	seed := w.config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	} else if w.match != nil {
+		// Every game of a match is different but can still be replayed.
+		seed += int64(w.match.Played())
+	}
+	level := arena.DefaultLevel(w.size.X, w.size.Y)
+	if w.config.Level != nil {
+		level = *w.config.Level
+	}
+	w.recorder = replay.NewRecorder(level.Options(w.config.Options(seed)))
+	w.arena = w.recorder
 
 	w.setDefaultMap()
+	w.addPauseMap()
+	w.bots = nil
+	w.lastDeath = ""
+	w.scoreMessage = ""
+	w.paused = false
+	for i, spawn := range level.Spawns[:w.config.Players+w.config.Bots] {
+		if i < w.config.Players {
+			w.addPlayerMap(i)
+		} else {
+			bot, _ := arena.NewController(w.config.BotKind, seed+int64(i))
+			w.bots = append(w.bots, bot)
+		}
+		w.arena.AddSnake(spawn.X, spawn.Y, spawn.Size, spawn.Heading)
+		if i >= w.config.Players && w.config.BotSpeed != arena.NormalSpeed {
+			w.arena.SetSnakeSpeed(i, w.config.BotSpeed)
+		}
+	}
+	w.state = w.arena.State()
+	w.baseLength = w.longestSnake()
+}
 
-	w.addP1Map()
-	w.arena.AddSnake(one.X, one.Y, 5, arena.EAST)
-
-	if w.players >= 2 {
-		w.addP2Map()
-		w.arena.AddSnake(one.X, one.Y*2, 5, arena.EAST)
+func (w ArenaWidget) longestSnake() int {
+	longest := 0
+	for _, snake := range w.state.Snakes {
+		if snake.Length() > longest {
+			longest = snake.Length()
+		}
 	}
+	return longest
+}
 
-	if w.players >= 3 {
-		w.addP3Map()
-		w.arena.AddSnake(one.X*2, one.Y, 5, arena.EAST)
+// speed is the current number of ticks per second. Progressive games do
+// not slow down below the starting speed when the snakes shrink.
+func (w ArenaWidget) speed() int {
+	speed := w.config.Speed
+	if w.config.Progressive && w.longestSnake() > w.baseLength {
+		speed += (w.longestSnake() - w.baseLength) / growthPerSpeedUp
+	}
+	if speed > maxSpeed {
+		speed = maxSpeed
 	}
+	return speed
+}
+
+func interval(speed int) time.Duration {
+	return time.Second / time.Duration(speed)
+}
 
-	if w.players >= 4 {
-		w.addP4Map()
-		w.arena.AddSnake(one.X*2, one.Y*2, 5, arena.EAST)
+// NextGame starts the next game of the match, or a new match once it is
+// decided.
+func (w *ArenaWidget) NextGame() {
+	if w.match.IsOver() {
+		w.match = arena.NewMatch(w.config.Rounds, w.config.Players+w.config.Bots)
 	}
+	w.ResetArena()
+}
+
+func (w *ArenaWidget) Record() replay.Record {
+	return w.recorder.Record()
 }
 
 func (w *ArenaWidget) Run() {
-	tick := time.Tick(100 * time.Millisecond)
-	event := eventChannel()
+	speed := w.speed()
+	ticker := time.NewTicker(interval(speed))
+	defer ticker.Stop()
 	w.running = true
+	w.resize()
 
 	for w.running {
-		termbox.Clear(0, 0)
+		w.screen.Clear()
 		w.Draw()
-		termbox.Flush()
+		w.screen.Flush()
 		select {
-		case ev := <-event:
+		case ev := <-w.screen.Events():
+			if ev.Type == screen.EventResize {
+				w.resize()
+			}
+			if w.entry != nil && ev.Type == screen.EventKey {
+				w.handleNameEntry(ev)
+				break
+			}
 			handleEvent(ev, w.KeyMap, w.RuneMap)
-		case <-tick:
+		case <-ticker.C:
+			if w.paused {
+				break
+			}
 			w.Tick()
+			if w.fastForward {
+				for i := 1; i < fastForwardTicks; i++ {
+					w.Tick()
+				}
+			}
+			if s := w.speed(); s != speed {
+				speed = s
+				ticker.Reset(interval(speed))
+			}
 		}
 	}
 }
@@ -171,59 +523,79 @@ func (w *ArenaWidget) Exit() {
 	w.running = false
 }
 
-func NewArenaWidget(ox, oy, x, y, players int) *ArenaWidget {
-	if x < 0 || y < 0 {
-		panic("Arena size must be positive.")
+// NewArenaWidget starts a game on s. Unless config has a level, the arena
+// fills the terminal.
+func NewArenaWidget(s screen.Screen, config Config) (*ArenaWidget, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	x, y := s.Size()
+	x, y = x-2, y-2-footerHeight-hudHeight(config.Players+config.Bots)
+	if config.Wide {
+		x /= 2
 	}
-	if players < 1 || players > 4 {
-		panic("Number of players must be between 1 and 4.")
+	if config.Level == nil {
+		if x < 1 || y < 1 {
+			return nil, errors.New("The terminal is too small.")
+		}
+		if err := arena.DefaultLevel(x, y).Validate(config.Players + config.Bots); err != nil {
+			return nil, errors.New("The terminal is too small for this many snakes.")
+		}
 	}
 
-	w := ArenaWidget{offset: Position{ox, oy}, size: Position{x, y}, players: players}
+	w := ArenaWidget{screen: s, size: Position{X: x, Y: y}, config: config}
+	w.match = arena.NewMatch(config.Rounds, config.Players+config.Bots)
+	if config.Level != nil {
+		w.size = Position{X: config.Level.Width, Y: config.Level.Height}
+	}
 	w.ResetArena()
-	return &w
+	return &w, nil
+}
+
+func (w *ArenaWidget) controls() Controls {
+	if w.config.Controls == nil {
+		return DefaultControls
+	}
+	return w.config.Controls
+}
+
+func (w *ArenaWidget) bind(action string, f func()) {
+	w.controls().bind(w.KeyMap, w.RuneMap, action, f)
 }
 
 func (w *ArenaWidget) setDefaultMap() {
 	w.KeyMap = KeyMap{}
 	w.RuneMap = RuneMap{}
 
-	w.KeyMap[termbox.KeyEsc] = func() { w.Exit() }
-	w.KeyMap[termbox.KeyEnter] = func() { w.ResetArena() }
-
+	w.bind("quit", func() { w.Exit() })
+	w.bind("restart", func() { w.NextGame() })
+	w.bind("controls", func() { w.toggleControls() })
 }
 
-func (w *ArenaWidget) addP1Map() {
-	w.KeyMap[termbox.KeyArrowRight] = func() { w.SetSnakeHeading(0, arena.EAST) }
-	w.KeyMap[termbox.KeyArrowUp] = func() { w.SetSnakeHeading(0, arena.NORTH) }
-	w.KeyMap[termbox.KeyArrowLeft] = func() { w.SetSnakeHeading(0, arena.WEST) }
-	w.KeyMap[termbox.KeyArrowDown] = func() { w.SetSnakeHeading(0, arena.SOUTH) }
+func (w *ArenaWidget) addPauseMap() {
+	w.bind("pause", func() { w.paused = !w.paused })
+	w.bind("step", func() { w.singleStep() })
 }
 
-func (w *ArenaWidget) addP2Map() {
-	w.RuneMap['D'] = func() { w.SetSnakeHeading(1, arena.EAST) }
-	w.RuneMap['W'] = func() { w.SetSnakeHeading(1, arena.NORTH) }
-	w.RuneMap['A'] = func() { w.SetSnakeHeading(1, arena.WEST) }
-	w.RuneMap['S'] = func() { w.SetSnakeHeading(1, arena.SOUTH) }
-	w.RuneMap['d'] = func() { w.SetSnakeHeading(1, arena.EAST) }
-	w.RuneMap['w'] = func() { w.SetSnakeHeading(1, arena.NORTH) }
-	w.RuneMap['a'] = func() { w.SetSnakeHeading(1, arena.WEST) }
-	w.RuneMap['s'] = func() { w.SetSnakeHeading(1, arena.SOUTH) }
+// singleStep pauses the game and advances it by exactly one tick.
+func (w *ArenaWidget) singleStep() {
+	w.paused = true
+	w.Tick()
 }
 
-func (w *ArenaWidget) addP3Map() {
-	w.RuneMap['L'] = func() { w.SetSnakeHeading(2, arena.EAST) }
-	w.RuneMap['I'] = func() { w.SetSnakeHeading(2, arena.NORTH) }
-	w.RuneMap['J'] = func() { w.SetSnakeHeading(2, arena.WEST) }
-	w.RuneMap['K'] = func() { w.SetSnakeHeading(2, arena.SOUTH) }
-	w.RuneMap['l'] = func() { w.SetSnakeHeading(2, arena.EAST) }
-	w.RuneMap['i'] = func() { w.SetSnakeHeading(2, arena.NORTH) }
-	w.RuneMap['j'] = func() { w.SetSnakeHeading(2, arena.WEST) }
-	w.RuneMap['k'] = func() { w.SetSnakeHeading(2, arena.SOUTH) }
+// toggleControls shows or hides the controls screen, pausing local games.
+func (w *ArenaWidget) toggleControls() {
+	w.showControls = !w.showControls
+	if w.showControls && w.remote == nil {
+		w.paused = true
+	}
 }
-func (w *ArenaWidget) addP4Map() {
-	w.RuneMap['6'] = func() { w.SetSnakeHeading(3, arena.EAST) }
-	w.RuneMap['8'] = func() { w.SetSnakeHeading(3, arena.NORTH) }
-	w.RuneMap['4'] = func() { w.SetSnakeHeading(3, arena.WEST) }
-	w.RuneMap['2'] = func() { w.SetSnakeHeading(3, arena.SOUTH) }
+
+// addPlayerMap binds the keys of player i to snake i.
+func (w *ArenaWidget) addPlayerMap(i int) {
+	p := fmt.Sprintf("p%d.", i+1)
+	w.bind(p+"right", func() { w.SetSnakeHeading(i, arena.EAST) })
+	w.bind(p+"up", func() { w.SetSnakeHeading(i, arena.NORTH) })
+	w.bind(p+"left", func() { w.SetSnakeHeading(i, arena.WEST) })
+	w.bind(p+"down", func() { w.SetSnakeHeading(i, arena.SOUTH) })
 }
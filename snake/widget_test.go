@@ -0,0 +1,248 @@
+package main
+
+import (
+	"flag"
+	"github.com/dragonfi/go-retro/snake/arena"
+	"github.com/dragonfi/go-retro/snake/screen"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files in testdata with the current output.")
+
+func snake(alive bool, segments ...arena.Position) arena.Snake {
+	return arena.Snake{Segments: segments, IsAlive: alive, Speed: arena.NormalSpeed}
+}
+
+// render draws the widget on an in-memory screen and compares it with
+// testdata/name.golden.
+func render(t *testing.T, name string, w ArenaWidget) {
+	m := screen.NewMemory(80, 24)
+	w.screen = m
+	w.layout()
+	w.Draw()
+	got := m.String()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(expected) {
+		t.Errorf("%s differs from %s, rerun with -update if the change is expected.\nExpected:\n%s\nGot:\n%s",
+			name, path, expected, got)
+	}
+}
+
+func TestDrawAliveAndDeadSnakes(t *testing.T) {
+	config := testConfig()
+	config.Players = 2
+	state := arena.State{
+		Size:  arena.Position{X: 30, Y: 8},
+		Walls: []arena.Position{{X: 14, Y: 3}, {X: 14, Y: 4}, {X: 14, Y: 5}},
+		Snakes: []arena.Snake{
+			snake(true, arena.Position{X: 8, Y: 5}, arena.Position{X: 7, Y: 5}, arena.Position{X: 6, Y: 5}, arena.Position{X: 5, Y: 5}),
+			snake(false, arena.Position{X: 20, Y: 6}, arena.Position{X: 21, Y: 6}, arena.Position{X: 22, Y: 6}),
+		},
+		Items: []arena.Item{{Position: arena.Position{X: 25, Y: 2}, Kind: arena.GROW}},
+	}
+	w := ArenaWidget{config: config, state: state, lastDeath: "Player 2 hit a wall"}
+	render(t, "snakes", w)
+}
+
+func TestDrawGameOver(t *testing.T) {
+	config := testConfig()
+	config.Players, config.Bots = 1, 1
+	state := arena.State{
+		Size: arena.Position{X: 40, Y: 14},
+		Seed: 42,
+		Snakes: []arena.Snake{
+			snake(false, arena.Position{X: 3, Y: 2}, arena.Position{X: 2, Y: 2}, arena.Position{X: 1, Y: 2}),
+			snake(true, arena.Position{X: 20, Y: 8}, arena.Position{X: 20, Y: 7}),
+		},
+		Ticks:      57,
+		GameIsOver: true,
+	}
+	state.Snakes[0].Score, state.Snakes[0].TicksSurvived = 4, 31
+	state.Snakes[1].Score, state.Snakes[1].Kills, state.Snakes[1].TicksSurvived = 2, 1, 57
+	w := ArenaWidget{config: config, state: state}
+	render(t, "game-over", w)
+}
+
+func TestDrawFourPlayerScores(t *testing.T) {
+	config := testConfig()
+	config.Players = 4
+	config.Items = "mixed"
+	state := arena.State{Size: arena.Position{X: 40, Y: 12}, Wrap: true, Ticks: 10}
+	for i := 0; i < 4; i++ {
+		s := snake(true, arena.Position{X: 30, Y: 2 + 2*i}, arena.Position{X: 31, Y: 2 + 2*i})
+		s.Score = 10 * i
+		state.Snakes = append(state.Snakes, s)
+	}
+	state.Snakes[1].Effects = map[arena.ItemKind]int{arena.SPEED_UP: 20}
+	state.Snakes[3].Effects = map[arena.ItemKind]int{arena.INVINCIBLE: 15, arena.REVERSE: 5}
+	w := ArenaWidget{config: config, state: state}
+	render(t, "four-players", w)
+}
+
+func TestDrawTinyArena(t *testing.T) {
+	state := arena.State{
+		Size:   arena.Position{X: 3, Y: 2},
+		Snakes: []arena.Snake{snake(true, arena.Position{X: 1, Y: 1}, arena.Position{X: 0, Y: 1})},
+	}
+	w := ArenaWidget{config: testConfig(), state: state}
+	render(t, "tiny", w)
+}
+
+func glyphsState() arena.State {
+	s := arena.State{
+		Size:  arena.Position{X: 12, Y: 5},
+		Walls: []arena.Position{{X: 9, Y: 1}, {X: 9, Y: 2}},
+		Snakes: []arena.Snake{
+			snake(true, arena.Position{X: 4, Y: 1}, arena.Position{X: 3, Y: 1}, arena.Position{X: 2, Y: 1}, arena.Position{X: 2, Y: 2}),
+			snake(false, arena.Position{X: 5, Y: 3}, arena.Position{X: 6, Y: 3}),
+		},
+		Items: []arena.Item{{Position: arena.Position{X: 10, Y: 4}, Kind: arena.BONUS}},
+	}
+	s.Snakes[0].Heading = arena.EAST
+	return s
+}
+
+func TestDrawWideBoxGlyphs(t *testing.T) {
+	config := testConfig()
+	config.Players, config.Wide, config.Glyphs = 2, true, "box"
+	render(t, "wide-box", ArenaWidget{config: config, state: glyphsState()})
+}
+
+func TestDrawBlockGlyphsWithWrap(t *testing.T) {
+	config := testConfig()
+	config.Players, config.Glyphs = 2, "block"
+	state := glyphsState()
+	state.Wrap = true
+	state.Snakes[0].Segments[0], state.Snakes[0].Heading = arena.Position{X: 2, Y: 0}, arena.NORTH
+	state.Snakes[0].Segments = state.Snakes[0].Segments[:3]
+	state.Snakes[0].Segments[1], state.Snakes[0].Segments[2] = arena.Position{X: 2, Y: 1}, arena.Position{X: 2, Y: 2}
+	render(t, "block-wrap", ArenaWidget{config: config, state: state})
+}
+
+func TestResizePausesTheGame(t *testing.T) {
+	state := arena.State{
+		Size:   arena.Position{X: 20, Y: 10},
+		Snakes: []arena.Snake{snake(true, arena.Position{X: 5, Y: 5}, arena.Position{X: 4, Y: 5})},
+	}
+	m := screen.NewMemory(80, 24)
+	w := ArenaWidget{screen: m, config: testConfig(), state: state}
+	w.resize()
+	if w.tooSmall || w.paused || w.offset != (Position{X: 30, Y: 8}) {
+		t.Error("Arena should be centered:", w.offset, w.tooSmall, w.paused)
+	}
+	m.Resize(100, 30)
+	w.resize()
+	if w.tooSmall || !w.paused {
+		t.Error("Game should pause when the terminal is resized.")
+	}
+	w.paused = false
+	m.Resize(40, 15)
+	w.resize()
+	if !w.tooSmall || !w.paused {
+		t.Error("Game should pause when the terminal is too small.")
+	}
+	w.Draw()
+	if expected, got := "The terminal is too small.\nResize it to at least 22x16.\n", m.String()[:56]; got != expected {
+		t.Errorf("Wrong message, expected: %q Got: %q", expected, got)
+	}
+	m.Resize(22, 16)
+	w.resize()
+	if w.tooSmall || w.offset != (Position{X: 1, Y: 4}) {
+		t.Error("Arena should fit again:", w.offset, w.tooSmall)
+	}
+}
+
+func TestDrawMonoThemeWithDistinctBodies(t *testing.T) {
+	config := testConfig()
+	config.Players, config.Bots, config.Theme = 2, 2, "mono"
+	state := arena.State{Size: arena.Position{X: 20, Y: 8}}
+	for i := 0; i < 4; i++ {
+		state.Snakes = append(state.Snakes,
+			snake(true, arena.Position{X: 4, Y: 1 + 2*i}, arena.Position{X: 3, Y: 1 + 2*i}, arena.Position{X: 2, Y: 1 + 2*i}))
+	}
+	render(t, "mono", ArenaWidget{config: config, state: state})
+}
+
+func TestThemeColors(t *testing.T) {
+	config := testConfig()
+	config.Theme = "colorblind"
+	state := arena.State{
+		Size:   arena.Position{X: 10, Y: 5},
+		Snakes: []arena.Snake{snake(true, arena.Position{X: 2, Y: 2}, arena.Position{X: 1, Y: 2})},
+		Items:  []arena.Item{{Position: arena.Position{X: 6, Y: 3}, Kind: arena.SHRINK}},
+	}
+	m := screen.NewMemory(80, 24)
+	w := ArenaWidget{screen: m, config: config, state: state}
+	w.layout()
+	w.Draw()
+	theme := colorThemes["colorblind"]
+	if got := m.Cell(w.offset.X+2, w.offset.Y+2).Fg; got != theme.snakes[0] {
+		t.Error("Wrong snake color. Expected:", theme.snakes[0], "Got:", got)
+	}
+	if got := m.Cell(w.offset.X+6, w.offset.Y+3).Fg; got != theme.items["shrink"] {
+		t.Error("Wrong item color. Expected:", theme.items["shrink"], "Got:", got)
+	}
+}
+
+func TestProgressiveSpeed(t *testing.T) {
+	config := testConfig()
+	config.Progressive, config.Speed = true, 1
+	w := ArenaWidget{config: config, baseLength: 5}
+	cases := []struct {
+		length int
+		speed  int
+	}{
+		{5, 1},
+		{2, 1},
+		{11, 3},
+		{200, maxSpeed},
+	}
+	for _, c := range cases {
+		segments := make([]arena.Position, c.length)
+		w.state.Snakes = []arena.Snake{snake(true, segments...)}
+		if got := w.speed(); got != c.speed {
+			t.Error("Wrong speed for length:", c.length, "Expected:", c.speed, "Got:", got)
+		}
+	}
+}
+
+func TestDistinctBodiesDifferFromWalls(t *testing.T) {
+	for name, g := range glyphThemes {
+		for i, body := range g.bodies {
+			for _, b := range []borderGlyphs{g.border, g.wrapBorder} {
+				if body[0] == b.horizontal || body[0] == b.vertical {
+					t.Error("Body of player", i+1, "looks like the border in:", name)
+				}
+			}
+			if body == g.wall {
+				t.Error("Body of player", i+1, "looks like a wall in:", name)
+			}
+		}
+	}
+}
+
+func TestDrawNameEntryWithFourSnakes(t *testing.T) {
+	config := testConfig()
+	config.Players, config.Bots = 1, 3
+	// The size NewArenaWidget picks for an 80x24 terminal.
+	state := arena.State{Size: arena.Position{X: 78, Y: 15}, Seed: 7, GameIsOver: true}
+	for i := 0; i < 4; i++ {
+		s := snake(false, arena.Position{X: 10, Y: 2 + 3*i}, arena.Position{X: 9, Y: 2 + 3*i})
+		s.Score, s.TicksSurvived = 12-i, 100-i
+		state.Snakes = append(state.Snakes, s)
+	}
+	w := ArenaWidget{config: config, state: state, entry: &nameEntry{players: []int{0}, name: []rune("Ann")}}
+	render(t, "name-entry", w)
+}